@@ -2,4 +2,4 @@ module sixnine/elgamal
 
 go 1.21.5
 
-require github.com/arnaucube/cryptofun v0.0.0-20190603183703-df33a4bbd574 // indirect
+require github.com/arnaucube/cryptofun v0.0.0-20190603183703-df33a4bbd574
@@ -63,14 +63,14 @@ func (eg EG) Decrypt(c [2]ecc.Point, privK *big.Int) (ecc.Point, error) {
 
 func main() {
 	ec := ecc.NewEC(big.NewInt(int64(1)), big.NewInt(int64(18)), big.NewInt(int64(19)))
-	g := ecc.Point{big.NewInt(int64(7)), big.NewInt(int64(11))}
+	g := ecc.Point{X: big.NewInt(int64(7)), Y: big.NewInt(int64(11))}
 	eg, _ := NewEG(ec, g)
 
 	privK := big.NewInt(int64(5))
 	pubK, _ := eg.PubK(privK)
 	fmt.Println("Public key: ", pubK)
 
-	m := ecc.Point{big.NewInt(int64(11)), big.NewInt(int64(12))}
+	m := ecc.Point{X: big.NewInt(int64(11)), Y: big.NewInt(int64(12))}
 	c, _ := eg.Encrypt(m, pubK, big.NewInt(int64(15)))
 
 	fmt.Println("Encryption result: ", c)
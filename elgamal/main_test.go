@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/arnaucube/cryptofun/ecc"
+)
+
+func point(x, y int64) ecc.Point {
+	return ecc.Point{X: big.NewInt(x), Y: big.NewInt(y)}
+}
+
+func samePoint(a, b ecc.Point) bool {
+	return a.X.Cmp(b.X) == 0 && a.Y.Cmp(b.Y) == 0
+}
+
+// Expected values were worked out by hand on y^2 = x^3 + x + 18 mod 19
+// with the base point (7, 11) of order 19.
+func TestElGamal(t *testing.T) {
+	ec := ecc.NewEC(big.NewInt(1), big.NewInt(18), big.NewInt(19))
+	eg, err := NewEG(ec, point(7, 11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eg.N.Cmp(big.NewInt(19)) != 0 {
+		t.Fatalf("order = %v, want 19", eg.N)
+	}
+
+	tests := []struct {
+		privK int64
+		r     int64
+		m     ecc.Point
+		pubK  ecc.Point
+		c     [2]ecc.Point
+	}{
+		{5, 15, point(11, 12), point(13, 9), [2]ecc.Point{point(8, 5), point(2, 16)}},
+		{3, 7, point(2, 16), point(2, 3), [2]ecc.Point{point(15, 8), point(7, 8)}},
+		{11, 2, point(8, 5), point(1, 18), [2]ecc.Point{point(11, 7), point(7, 8)}},
+		{7, 4, point(13, 9), point(15, 8), [2]ecc.Point{point(8, 14), point(13, 10)}},
+	}
+	for _, test := range tests {
+		privK := big.NewInt(test.privK)
+
+		pubK, err := eg.PubK(privK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !samePoint(pubK, test.pubK) {
+			t.Errorf("PubK(%d) = %v, want %v", test.privK, pubK, test.pubK)
+		}
+
+		c, err := eg.Encrypt(test.m, pubK, big.NewInt(test.r))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !samePoint(c[0], test.c[0]) || !samePoint(c[1], test.c[1]) {
+			t.Errorf("Encrypt(%v, r=%d) = %v, want %v", test.m, test.r, c, test.c)
+		}
+
+		d, err := eg.Decrypt(c, privK)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !samePoint(d, test.m) {
+			t.Errorf("Decrypt(%v) = %v, want %v", c, d, test.m)
+		}
+	}
+}
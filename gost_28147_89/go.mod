@@ -0,0 +1,3 @@
+module six_nine/gost_28147_89
+
+go 1.20
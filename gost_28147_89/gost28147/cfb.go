@@ -0,0 +1,93 @@
+package gost28147
+
+import "crypto/cipher"
+
+// Gamma with feedback mode: every block of gamma is the encryption of the
+// previous ciphertext block, the first one is the encryption of the
+// synchronization message.
+
+type cfb struct {
+	b       cipher.Block
+	next    []byte
+	gamma   []byte
+	used    int
+	decrypt bool
+	meshing *gostCipher
+	count   int
+}
+
+// NewCFBEncrypter returns a cipher.Stream which encrypts in the gamma
+// with feedback mode of GOST 28147-89. The iv is the synchronization
+// message and must be one block long.
+func NewCFBEncrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, false)
+}
+
+// NewCFBDecrypter returns a cipher.Stream which decrypts in the gamma
+// with feedback mode of GOST 28147-89.
+func NewCFBDecrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, true)
+}
+
+// NewCFBEncrypterWithKeyMeshing is like NewCFBEncrypter but applies
+// CryptoPro key meshing every 1024 bytes. The block must come from
+// NewCipher; it is copied, so meshing does not change b itself.
+func NewCFBEncrypterWithKeyMeshing(b cipher.Block, iv []byte) cipher.Stream {
+	c := meshingCipher(b)
+	x := newCFB(c, iv, false)
+	x.meshing = c
+	return x
+}
+
+// NewCFBDecrypterWithKeyMeshing is like NewCFBDecrypter but applies
+// CryptoPro key meshing every 1024 bytes.
+func NewCFBDecrypterWithKeyMeshing(b cipher.Block, iv []byte) cipher.Stream {
+	c := meshingCipher(b)
+	x := newCFB(c, iv, true)
+	x.meshing = c
+	return x
+}
+
+func newCFB(b cipher.Block, iv []byte, decrypt bool) *cfb {
+	bs := b.BlockSize()
+	if len(iv) != bs {
+		panic("gost28147: IV length must equal block size")
+	}
+
+	x := &cfb{
+		b:       b,
+		next:    make([]byte, bs),
+		gamma:   make([]byte, bs),
+		used:    bs,
+		decrypt: decrypt,
+	}
+	copy(x.next, iv)
+
+	return x
+}
+
+func (x *cfb) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("gost28147: output smaller than input")
+	}
+	for i := range src {
+		if x.used == len(x.gamma) {
+			if x.meshing != nil && x.count == meshingPeriod {
+				x.meshing.meshKey(x.next)
+				x.count = 0
+			}
+			x.b.Encrypt(x.gamma, x.next)
+			x.used = 0
+			x.count += len(x.gamma)
+		}
+		if x.decrypt {
+			c := src[i]
+			dst[i] = c ^ x.gamma[x.used]
+			x.next[x.used] = c
+		} else {
+			dst[i] = src[i] ^ x.gamma[x.used]
+			x.next[x.used] = dst[i]
+		}
+		x.used++
+	}
+}
@@ -0,0 +1,59 @@
+package gost28147
+
+import "crypto/cipher"
+
+// Simple replacement mode: every 64-bit block is encrypted on its own.
+
+type ecb struct {
+	b cipher.Block
+}
+
+type ecbEncrypter ecb
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts in the
+// simple replacement mode of GOST 28147-89.
+func NewECBEncrypter(b cipher.Block) cipher.BlockMode {
+	return &ecbEncrypter{b: b}
+}
+
+func (x *ecbEncrypter) BlockSize() int {
+	return x.b.BlockSize()
+}
+
+func (x *ecbEncrypter) CryptBlocks(dst, src []byte) {
+	bs := x.b.BlockSize()
+	if len(src)%bs != 0 {
+		panic("gost28147: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("gost28147: output smaller than input")
+	}
+	for i := 0; i < len(src); i += bs {
+		x.b.Encrypt(dst[i:i+bs], src[i:i+bs])
+	}
+}
+
+type ecbDecrypter ecb
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts in the
+// simple replacement mode of GOST 28147-89.
+func NewECBDecrypter(b cipher.Block) cipher.BlockMode {
+	return &ecbDecrypter{b: b}
+}
+
+func (x *ecbDecrypter) BlockSize() int {
+	return x.b.BlockSize()
+}
+
+func (x *ecbDecrypter) CryptBlocks(dst, src []byte) {
+	bs := x.b.BlockSize()
+	if len(src)%bs != 0 {
+		panic("gost28147: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("gost28147: output smaller than input")
+	}
+	for i := 0; i < len(src); i += bs {
+		x.b.Decrypt(dst[i:i+bs], src[i:i+bs])
+	}
+}
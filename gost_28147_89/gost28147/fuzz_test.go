@@ -0,0 +1,62 @@
+package gost28147
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+// FuzzRoundTrip encrypts and decrypts data in every mode and wraps the key
+// with itself. The key and the synchronization message are cut or
+// zero-padded to size, and the S-box is picked from ParamSets.
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(testKey(), []byte("syncmsg!"), testData(3*BlockSize+5), uint8(0))
+	f.Add([]byte{}, []byte{}, []byte{}, uint8(1))
+	f.Add(testKey(), []byte{1}, testData(meshingPeriod+BlockSize+1), uint8(5))
+
+	f.Fuzz(func(t *testing.T, keyData, ivData, data []byte, sbox uint8) {
+		key := make([]byte, KeySize)
+		copy(key, keyData)
+		iv := make([]byte, BlockSize)
+		copy(iv, ivData)
+
+		s := ParamSets[int(sbox)%len(ParamSets)].SBox
+		c, err := NewCipher(key, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blocks := data[:len(data)/BlockSize*BlockSize]
+		buf := make([]byte, len(blocks))
+		NewECBEncrypter(c).CryptBlocks(buf, blocks)
+		NewECBDecrypter(c).CryptBlocks(buf, buf)
+		if !bytes.Equal(buf, blocks) {
+			t.Fatalf("ECB: decrypted %x, want %x", buf, blocks)
+		}
+
+		for _, mode := range []struct {
+			name     string
+			enc, dec func(cipher.Block, []byte) cipher.Stream
+		}{
+			{"CTR", NewCTR, NewCTR},
+			{"CTR with key meshing", NewCTRWithKeyMeshing, NewCTRWithKeyMeshing},
+			{"CFB", NewCFBEncrypter, NewCFBDecrypter},
+			{"CFB with key meshing", NewCFBEncrypterWithKeyMeshing, NewCFBDecrypterWithKeyMeshing},
+		} {
+			buf := make([]byte, len(data))
+			mode.enc(c, iv).XORKeyStream(buf, data)
+			mode.dec(c, iv).XORKeyStream(buf, buf)
+			if !bytes.Equal(buf, data) {
+				t.Fatalf("%s: decrypted %x, want %x", mode.name, buf, data)
+			}
+		}
+
+		wrapped, err := Wrap(key, iv, key, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cek, err := Unwrap(key, wrapped, s); err != nil || !bytes.Equal(cek, key) {
+			t.Fatalf("Unwrap(Wrap(%x)) = %x, %v", key, cek, err)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Package gost28147 implements the GOST 28147-89 block cipher.
+package gost28147
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	BlockSize = 8
+	KeySize   = 32
+)
+
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+	return "gost28147: invalid key size " + strconv.Itoa(int(k))
+}
+
+type gostCipher struct {
+	k [8]uint32
+	t *[4][256]uint32
+}
+
+// NewCipher creates and returns a new cipher.Block. The key must be
+// 256 bits long. A nil sbox selects SBoxTest.
+func NewCipher(key []byte, sbox *SBox) (cipher.Block, error) {
+	return newCipher(key, sbox)
+}
+
+func newCipher(key []byte, sbox *SBox) (*gostCipher, error) {
+	if len(key) != KeySize {
+		return nil, KeySizeError(len(key))
+	}
+	if sbox == nil {
+		sbox = SBoxTest
+	}
+
+	c := &gostCipher{t: expandSBox(sbox)}
+	for i := range c.k {
+		c.k[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+
+	return c, nil
+}
+
+func (c *gostCipher) BlockSize() int {
+	return BlockSize
+}
+
+func (c *gostCipher) Encrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("gost28147: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("gost28147: output not full block")
+	}
+	n := binary.LittleEndian.Uint64(src)
+	binary.LittleEndian.PutUint64(dst, c.encode32cycle(n))
+}
+
+func (c *gostCipher) Decrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("gost28147: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("gost28147: output not full block")
+	}
+	n := binary.LittleEndian.Uint64(src)
+	binary.LittleEndian.PutUint64(dst, c.decode32cycle(n))
+}
+
+// expandSBox merges pairs of S-box rows with the 11-bit rotation of the
+// round function into four byte-indexed tables.
+func expandSBox(sbox *SBox) *[4][256]uint32 {
+	var t [4][256]uint32
+	for i := range t {
+		for b := range t[i] {
+			lo := uint32(sbox[2*i][b&0xF])
+			hi := uint32(sbox[2*i+1][b>>4])
+			t[i][b] = bits.RotateLeft32((lo|hi<<4)<<(8*i), 11)
+		}
+	}
+	return &t
+}
+
+// f is the round function: the S-box substitution of x rotated left by
+// 11 bits.
+func (c *gostCipher) f(x uint32) uint32 {
+	return c.t[0][byte(x)] ^ c.t[1][byte(x>>8)] ^
+		c.t[2][byte(x>>16)] ^ c.t[3][byte(x>>24)]
+}
+
+// The cycles below keep N1 and N2 in n1 and n2 and swap the roles of the
+// two registers instead of their contents after every step. A block holds
+// N1 in its low half.
+
+func (c *gostCipher) encode32cycle(n uint64) uint64 {
+	n1, n2 := uint32(n), uint32(n>>32)
+
+	for k := 1; k <= 3; k++ {
+		for j := 0; j < 8; j += 2 {
+			n2 ^= c.f(n1 + c.k[j])
+			n1 ^= c.f(n2 + c.k[j+1])
+		}
+	}
+
+	for j := 7; j > 0; j -= 2 {
+		n2 ^= c.f(n1 + c.k[j])
+		n1 ^= c.f(n2 + c.k[j-1])
+	}
+
+	return uint64(n1)<<32 | uint64(n2)
+}
+
+func (c *gostCipher) decode32cycle(n uint64) uint64 {
+	n1, n2 := uint32(n), uint32(n>>32)
+
+	for j := 0; j < 8; j += 2 {
+		n2 ^= c.f(n1 + c.k[j])
+		n1 ^= c.f(n2 + c.k[j+1])
+	}
+
+	for k := 1; k <= 3; k++ {
+		for j := 7; j > 0; j -= 2 {
+			n2 ^= c.f(n1 + c.k[j])
+			n1 ^= c.f(n2 + c.k[j-1])
+		}
+	}
+
+	return uint64(n1)<<32 | uint64(n2)
+}
+
+func (c *gostCipher) mac16cycle(n uint64) uint64 {
+	n1, n2 := uint32(n), uint32(n>>32)
+
+	for k := 1; k <= 2; k++ {
+		for j := 0; j < 8; j += 2 {
+			n2 ^= c.f(n1 + c.k[j])
+			n1 ^= c.f(n2 + c.k[j+1])
+		}
+	}
+
+	return uint64(n2)<<32 | uint64(n1)
+}
@@ -0,0 +1,261 @@
+package gost28147
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+	"testing"
+)
+
+// referenceF is the round function computed nibble by nibble, as the
+// standard writes it.
+func referenceF(sbox *SBox, x uint32) uint32 {
+	var s uint32
+	for i := 0; i < 8; i++ {
+		s |= uint32(sbox[i][(x>>(4*i))&0xF]) << (4 * i)
+	}
+	return bits.RotateLeft32(s, 11)
+}
+
+// referenceCipher follows the text of the standard step by step: every
+// basic step moves N1 to N2 and the new value to N1, and the last step of
+// the 32-round cycles writes its value to N2 instead. It is slow and only
+// serves as an independent reference for the tests.
+type referenceCipher struct {
+	k    [8]uint32
+	sbox *SBox
+}
+
+func newReferenceCipher(key []byte, sbox *SBox) *referenceCipher {
+	c := &referenceCipher{sbox: sbox}
+	for i := range c.k {
+		c.k[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	return c
+}
+
+var (
+	encryptOrder = []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7, 7, 6, 5, 4, 3, 2, 1, 0}
+	decryptOrder = []int{0, 1, 2, 3, 4, 5, 6, 7, 7, 6, 5, 4, 3, 2, 1, 0, 7, 6, 5, 4, 3, 2, 1, 0, 7, 6, 5, 4, 3, 2, 1, 0}
+	macOrder     = []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2, 3, 4, 5, 6, 7}
+)
+
+// cycle runs the basic steps with the key words in order on a block that
+// holds N1 in its low half.
+func (c *referenceCipher) cycle(n uint64, order []int, last bool) uint64 {
+	n1, n2 := uint32(n), uint32(n>>32)
+	for _, j := range order {
+		s := referenceF(c.sbox, n1+c.k[j]) ^ n2
+		n2, n1 = n1, s
+	}
+	if last {
+		n1, n2 = n2, n1
+	}
+	return uint64(n2)<<32 | uint64(n1)
+}
+
+func (c *referenceCipher) BlockSize() int { return BlockSize }
+
+func (c *referenceCipher) Encrypt(dst, src []byte) {
+	binary.LittleEndian.PutUint64(dst, c.cycle(binary.LittleEndian.Uint64(src), encryptOrder, true))
+}
+
+func (c *referenceCipher) Decrypt(dst, src []byte) {
+	binary.LittleEndian.PutUint64(dst, c.cycle(binary.LittleEndian.Uint64(src), decryptOrder, true))
+}
+
+// mac16 is the 16-round cycle of the imitovstavka mode.
+func (c *referenceCipher) mac16(n uint64) uint64 {
+	return c.cycle(n, macOrder, false)
+}
+
+func TestRoundFunction(t *testing.T) {
+	// g[k](a) from RFC 8891, section A.2.
+	vectors := []struct{ k, a, want uint32 }{
+		{0x87654321, 0xfedcba98, 0xfdcbc20c},
+		{0xfdcbc20c, 0x87654321, 0x7e791a4b},
+		{0x7e791a4b, 0xfdcbc20c, 0xc76549ec},
+		{0xc76549ec, 0x7e791a4b, 0x9791c849},
+	}
+
+	c, err := newCipher(make([]byte, KeySize), SBoxTC26Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		if got := c.f(v.a + v.k); got != v.want {
+			t.Errorf("g[%08x](%08x) = %08x, want %08x", v.k, v.a, got, v.want)
+		}
+	}
+
+	for _, p := range ParamSets {
+		c, err := newCipher(make([]byte, KeySize), p.SBox)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for x := uint32(0); x < 1<<20; x++ {
+			y := x * 0x9E3779B1
+			if got, want := c.f(y), referenceF(p.SBox, y); got != want {
+				t.Fatalf("%s: f(%08x) = %08x, want %08x", p.Name, y, got, want)
+			}
+		}
+	}
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func decodeHex(t testing.TB, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestMagmaVectors(t *testing.T) {
+	// GOST R 34.12-2015 Magma is GOST 28147-89 with the TC26 Z S-box and
+	// big-endian byte order. The first pair is from RFC 8891, section A.3,
+	// the rest are the ECB example of GOST R 34.13-2015, section A.2.1.
+	key := decodeHex(t, "ffeeddccbbaa99887766554433221100f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	tests := []struct {
+		pt string
+		ct string
+	}{
+		{"fedcba9876543210", "4ee901e5c2d8ca3d"},
+		{"92def06b3c130a59", "2b073f0494f372a0"},
+		{"db54c704f8189d20", "de70e715d3556e48"},
+		{"4a98fb2e67a8024c", "11d8d9e9eacfbc1e"},
+		{"8912409b17b57e41", "7c68260996c67efb"},
+	}
+
+	var le []byte
+	for i := 0; i < len(key); i += 4 {
+		le = append(le, reverse(key[i:i+4])...)
+	}
+	c, err := NewCipher(le, SBoxTC26Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, BlockSize)
+	for _, b := range []cipher.Block{c, newReferenceCipher(le, SBoxTC26Z)} {
+		for _, test := range tests {
+			pt, ct := decodeHex(t, test.pt), decodeHex(t, test.ct)
+			b.Encrypt(got, reverse(pt))
+			if !bytes.Equal(reverse(got), ct) {
+				t.Errorf("%T: Encrypt(%s) = %x, want %s", b, test.pt, reverse(got), test.ct)
+			}
+			b.Decrypt(got, reverse(ct))
+			if !bytes.Equal(reverse(got), pt) {
+				t.Errorf("%T: Decrypt(%s) = %x, want %s", b, test.ct, reverse(got), test.pt)
+			}
+		}
+	}
+}
+
+func TestReferenceCipher(t *testing.T) {
+	for _, p := range ParamSets {
+		c, _ := newCipher(testKey(), p.SBox)
+		ref := newReferenceCipher(testKey(), p.SBox)
+		for i := 0; i < 1000; i++ {
+			n := uint64(i) * 0x9E3779B97F4A7C15
+			if got, want := c.encode32cycle(n), ref.cycle(n, encryptOrder, true); got != want {
+				t.Fatalf("%s: encrypt(%016x) = %016x, want %016x", p.Name, n, got, want)
+			}
+			if got, want := c.decode32cycle(n), ref.cycle(n, decryptOrder, true); got != want {
+				t.Fatalf("%s: decrypt(%016x) = %016x, want %016x", p.Name, n, got, want)
+			}
+			if got, want := c.mac16cycle(n), ref.mac16(n); got != want {
+				t.Fatalf("%s: mac16(%016x) = %016x, want %016x", p.Name, n, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	c, _ := NewCipher(make([]byte, KeySize), SBoxCryptoProA)
+	buf := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(buf, buf)
+	}
+}
+
+// previousCipher is the implementation this package had before the
+// table-driven round function, kept for BenchmarkPreviousEncrypt. It
+// shifts the substituted word left by 11 bits instead of rotating it, as
+// it did then, so its output is not GOST 28147-89.
+type previousCipher struct {
+	k    [8]uint32
+	sbox *SBox
+}
+
+func (c *previousCipher) mainStep(n uint64, x uint32) uint64 {
+	n1 := uint32(n)
+	n2 := uint32(n >> 32)
+
+	var s uint32 = n1 + x
+	var sn uint32 = 0
+	for i := 0; i < 8; i++ {
+		var si uint32 = (s >> (4 * i)) & 0xF
+		si = uint32(c.sbox[i][si])
+		sn |= si << (4 * i)
+	}
+
+	sn <<= 11
+
+	sn ^= n2
+
+	n2 = n1
+	n1 = sn
+
+	return (uint64(n2) << 32) | uint64(n1)
+}
+
+func (c *previousCipher) encode32cycle(n uint64) uint64 {
+	for k := 1; k <= 3; k++ {
+		for j := 0; j < 8; j++ {
+			n = c.mainStep(n, c.k[j])
+		}
+	}
+
+	for j := 7; j >= 0; j-- {
+		n = c.mainStep(n, c.k[j])
+	}
+
+	return (n << 32) | (n >> 32)
+}
+
+func (c *previousCipher) Encrypt(dst, src []byte) {
+	n := binary.LittleEndian.Uint64(src)
+	binary.LittleEndian.PutUint64(dst, c.encode32cycle(n))
+}
+
+// BenchmarkPreviousEncrypt runs the previous implementation for
+// comparison with BenchmarkEncrypt.
+func BenchmarkPreviousEncrypt(b *testing.B) {
+	c := &previousCipher{sbox: SBoxCryptoProA}
+	buf := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(buf, buf)
+	}
+}
+
+func BenchmarkCTR(b *testing.B) {
+	c, _ := NewCipher(make([]byte, KeySize), SBoxCryptoProA)
+	s := NewCTR(c, make([]byte, BlockSize))
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		s.XORKeyStream(buf, buf)
+	}
+}
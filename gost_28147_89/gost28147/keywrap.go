@@ -0,0 +1,138 @@
+package gost28147
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Key wrap of RFC 4357, section 6. A wrapped key is UKM | CEK_ENC |
+// CEK_MAC: the 8-byte user keying material, the content encryption key
+// encrypted in simple replacement mode and its 32-bit imitovstavka
+// computed with the UKM as the initial state.
+
+const (
+	UKMSize        = 8
+	WrappedKeySize = UKMSize + KeySize + 4
+)
+
+var ErrUnwrap = errors.New("gost28147: wrapped key MAC mismatch")
+
+// Wrap wraps cek under kek with the GOST 28147-89 key wrap. The ukm must
+// be 8 random bytes, unique for the kek.
+func Wrap(kek, ukm, cek []byte, sbox *SBox) ([]byte, error) {
+	if len(ukm) != UKMSize {
+		return nil, errors.New("gost28147: UKM must be 8 bytes long")
+	}
+	if len(cek) != KeySize {
+		return nil, KeySizeError(len(cek))
+	}
+
+	c, err := newCipher(kek, sbox)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := NewMAC(kek, sbox, 32, ukm)
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(cek)
+
+	wrapped := make([]byte, 0, WrappedKeySize)
+	wrapped = append(wrapped, ukm...)
+	wrapped = append(wrapped, cek...)
+	NewECBEncrypter(c).CryptBlocks(wrapped[UKMSize:], cek)
+
+	return mac.Sum(wrapped), nil
+}
+
+// Unwrap reverses Wrap. It returns ErrUnwrap if the MAC of the unwrapped
+// key does not match.
+func Unwrap(kek, wrapped []byte, sbox *SBox) ([]byte, error) {
+	if len(wrapped) != WrappedKeySize {
+		return nil, errors.New("gost28147: wrapped key must be 44 bytes long")
+	}
+	ukm := wrapped[:UKMSize]
+	enc := wrapped[UKMSize : UKMSize+KeySize]
+	tag := wrapped[UKMSize+KeySize:]
+
+	c, err := newCipher(kek, sbox)
+	if err != nil {
+		return nil, err
+	}
+	cek := make([]byte, KeySize)
+	NewECBDecrypter(c).CryptBlocks(cek, enc)
+
+	mac, err := NewMAC(kek, sbox, 32, ukm)
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(cek)
+	if !mac.Verify(tag) {
+		return nil, ErrUnwrap
+	}
+
+	return cek, nil
+}
+
+// WrapCryptoPro wraps cek with the CryptoPro key wrap: the same as Wrap,
+// but under the kek diversified by the ukm.
+func WrapCryptoPro(kek, ukm, cek []byte, sbox *SBox) ([]byte, error) {
+	if len(ukm) != UKMSize {
+		return nil, errors.New("gost28147: UKM must be 8 bytes long")
+	}
+	kekUKM, err := DiversifyKEK(kek, ukm, sbox)
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(kekUKM, ukm, cek, sbox)
+}
+
+// UnwrapCryptoPro reverses WrapCryptoPro.
+func UnwrapCryptoPro(kek, wrapped []byte, sbox *SBox) ([]byte, error) {
+	if len(wrapped) != WrappedKeySize {
+		return nil, errors.New("gost28147: wrapped key must be 44 bytes long")
+	}
+	kekUKM, err := DiversifyKEK(kek, wrapped[:UKMSize], sbox)
+	if err != nil {
+		return nil, err
+	}
+	return Unwrap(kekUKM, wrapped, sbox)
+}
+
+// DiversifyKEK implements the CryptoPro KEK diversification of RFC 4357,
+// section 6.5. Each of the eight rounds encrypts the key in CFB mode
+// under itself, with an IV made of two sums of the key words selected by
+// the bits of one UKM byte and by their complements.
+func DiversifyKEK(kek, ukm []byte, sbox *SBox) ([]byte, error) {
+	if len(kek) != KeySize {
+		return nil, KeySizeError(len(kek))
+	}
+	if len(ukm) != UKMSize {
+		return nil, errors.New("gost28147: UKM must be 8 bytes long")
+	}
+
+	k := make([]byte, KeySize)
+	copy(k, kek)
+	for i := 0; i < UKMSize; i++ {
+		var s1, s2 uint32
+		for j := 0; j < 8; j++ {
+			kj := binary.LittleEndian.Uint32(k[4*j:])
+			if (ukm[i]>>j)&1 == 1 {
+				s1 += kj
+			} else {
+				s2 += kj
+			}
+		}
+		var iv [BlockSize]byte
+		binary.LittleEndian.PutUint32(iv[:4], s1)
+		binary.LittleEndian.PutUint32(iv[4:], s2)
+
+		c, err := newCipher(k, sbox)
+		if err != nil {
+			return nil, err
+		}
+		NewCFBEncrypter(c, iv[:]).XORKeyStream(k, k)
+	}
+
+	return k, nil
+}
@@ -0,0 +1,109 @@
+package gost28147
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// MAC computes the imitovstavka of GOST 28147-89: every block is added
+// to the running state, which then goes through the 16-round cycle.
+// A final partial block is padded with zeroes, and a message of a single
+// block is followed by a zero block, so at least two blocks are always
+// processed. It implements hash.Hash.
+type MAC struct {
+	c      *gostCipher
+	size   int
+	iv     uint64
+	s      uint64
+	buf    [BlockSize]byte
+	n      int
+	blocks int
+}
+
+// NewMAC returns a MAC producing size bits, from 1 to 64, under the given
+// key and S-box. The iv is the initial state and may be nil, in which case
+// it is zero.
+func NewMAC(key []byte, sbox *SBox, size int, iv []byte) (*MAC, error) {
+	if size < 1 || size > 64 {
+		return nil, errors.New("gost28147: MAC size must be from 1 to 64 bits")
+	}
+	if iv != nil && len(iv) != BlockSize {
+		return nil, errors.New("gost28147: IV length must equal block size")
+	}
+
+	c, err := newCipher(key, sbox)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MAC{c: c, size: size}
+	if iv != nil {
+		m.iv = binary.LittleEndian.Uint64(iv)
+	}
+	m.Reset()
+
+	return m, nil
+}
+
+func (m *MAC) BlockSize() int {
+	return BlockSize
+}
+
+// Size returns the number of bytes Sum appends: the MAC size rounded up
+// to whole bytes.
+func (m *MAC) Size() int {
+	return (m.size + 7) / 8
+}
+
+func (m *MAC) Reset() {
+	m.s = m.iv
+	m.n = 0
+	m.blocks = 0
+}
+
+func (m *MAC) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for len(p) > 0 {
+		k := copy(m.buf[m.n:], p)
+		m.n += k
+		p = p[k:]
+		if m.n == BlockSize {
+			m.s = m.c.mac16cycle(m.s ^ binary.LittleEndian.Uint64(m.buf[:]))
+			m.n = 0
+			m.blocks++
+		}
+	}
+	return n, nil
+}
+
+// Sum appends the current MAC to b. It does not change the state.
+func (m *MAC) Sum(b []byte) []byte {
+	s := m.s
+	blocks := m.blocks
+
+	if m.n > 0 {
+		var last [BlockSize]byte
+		copy(last[:], m.buf[:m.n])
+		s = m.c.mac16cycle(s ^ binary.LittleEndian.Uint64(last[:]))
+		blocks++
+	}
+	if blocks == 1 {
+		s = m.c.mac16cycle(s)
+	}
+
+	var out [BlockSize]byte
+	binary.LittleEndian.PutUint64(out[:], s)
+	mac := out[:m.Size()]
+	if rem := m.size % 8; rem != 0 {
+		mac[len(mac)-1] &= 1<<rem - 1
+	}
+
+	return append(b, mac...)
+}
+
+// Verify reports whether mac is the MAC of the data written so far. The
+// comparison takes constant time.
+func (m *MAC) Verify(mac []byte) bool {
+	return subtle.ConstantTimeCompare(m.Sum(nil), mac) == 1
+}
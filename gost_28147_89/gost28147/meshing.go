@@ -0,0 +1,42 @@
+package gost28147
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// CryptoPro key meshing (RFC 4357, section 2.3.2): after every 1024 bytes
+// of CFB or gamma output the key is replaced by the decryption of a fixed
+// constant under the current key, and the feedback register is encrypted
+// under the new key.
+
+const meshingPeriod = 1024
+
+var keyMeshingKey = [KeySize]byte{
+	0x69, 0x00, 0x72, 0x22, 0x64, 0xC9, 0x04, 0x23,
+	0x8D, 0x3A, 0xDB, 0x96, 0x46, 0xE9, 0x2A, 0xC4,
+	0x18, 0xFE, 0xAC, 0x94, 0x00, 0xED, 0x07, 0x12,
+	0xC0, 0x86, 0xDC, 0xC2, 0xEF, 0x4C, 0xA9, 0x2B,
+}
+
+// meshKey rekeys c and encrypts iv in place under the new key.
+func (c *gostCipher) meshKey(iv []byte) {
+	var key [KeySize]byte
+	for i := 0; i < KeySize; i += BlockSize {
+		c.Decrypt(key[i:], keyMeshingKey[i:])
+	}
+	for i := range c.k {
+		c.k[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	c.Encrypt(iv, iv)
+}
+
+// meshingCipher returns a private copy of b for a mode to rekey.
+func meshingCipher(b cipher.Block) *gostCipher {
+	c, ok := b.(*gostCipher)
+	if !ok {
+		panic("gost28147: key meshing needs a cipher from NewCipher")
+	}
+	cc := *c
+	return &cc
+}
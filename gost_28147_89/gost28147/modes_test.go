@@ -0,0 +1,484 @@
+package gost28147
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"testing"
+)
+
+// No published vectors exist for the modes with these S-boxes, so the
+// tests below check them against their definitions, each other and the
+// output of GnuTLS and libgcrypt.
+
+func testKey() []byte {
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i*13 + 1)
+	}
+	return key
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func TestECB(t *testing.T) {
+	c, _ := NewCipher(testKey(), SBoxCryptoProA)
+	pt := testData(8 * BlockSize)
+	ct := make([]byte, len(pt))
+	NewECBEncrypter(c).CryptBlocks(ct, pt)
+
+	want := make([]byte, BlockSize)
+	for i := 0; i < len(pt); i += BlockSize {
+		c.Encrypt(want, pt[i:])
+		if !bytes.Equal(ct[i:i+BlockSize], want) {
+			t.Fatalf("block %d = %x, want %x", i/BlockSize, ct[i:i+BlockSize], want)
+		}
+	}
+
+	got := make([]byte, len(ct))
+	NewECBDecrypter(c).CryptBlocks(got, ct)
+	if !bytes.Equal(got, pt) {
+		t.Errorf("ECB round trip = %x, want %x", got, pt)
+	}
+}
+
+func TestCTR(t *testing.T) {
+	c, _ := NewCipher(testKey(), SBoxCryptoProA)
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pt := testData(3*BlockSize + 5)
+
+	// The gamma from the definition: E(E(iv) stepped by C2 and C1).
+	var want []byte
+	s := make([]byte, BlockSize)
+	c.Encrypt(s, iv)
+	n3 := uint64(binary.LittleEndian.Uint32(s[:4]))
+	n4 := uint64(binary.LittleEndian.Uint32(s[4:]))
+	for len(want) < len(pt) {
+		n3 = (n3 + c2) % (1 << 32)
+		n4 = (n4 + c1) % (1<<32 - 1)
+		g := make([]byte, BlockSize)
+		binary.LittleEndian.PutUint32(g[:4], uint32(n3))
+		binary.LittleEndian.PutUint32(g[4:], uint32(n4))
+		c.Encrypt(g, g)
+		want = append(want, g...)
+	}
+	for i := range pt {
+		want[i] ^= pt[i]
+	}
+	want = want[:len(pt)]
+
+	got := make([]byte, len(pt))
+	stream := NewCTR(c, iv)
+	stream.XORKeyStream(got[:5], pt[:5])
+	stream.XORKeyStream(got[5:], pt[5:])
+	if !bytes.Equal(got, want) {
+		t.Errorf("gamma = %x, want %x", got, want)
+	}
+
+	NewCTR(c, iv).XORKeyStream(got, got)
+	if !bytes.Equal(got, pt) {
+		t.Errorf("gamma round trip = %x, want %x", got, pt)
+	}
+}
+
+func TestCFB(t *testing.T) {
+	c, _ := NewCipher(testKey(), SBoxCryptoProA)
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pt := testData(1001)
+
+	// Gamma with feedback is full-block CFB.
+	want := make([]byte, len(pt))
+	cipher.NewCFBEncrypter(c, iv).XORKeyStream(want, pt)
+
+	got := make([]byte, len(pt))
+	stream := NewCFBEncrypter(c, iv)
+	stream.XORKeyStream(got[:3], pt[:3])
+	stream.XORKeyStream(got[3:], pt[3:])
+	if !bytes.Equal(got, want) {
+		t.Errorf("CFB = %x, want %x", got, want)
+	}
+
+	NewCFBDecrypter(c, iv).XORKeyStream(got, got)
+	if !bytes.Equal(got, pt) {
+		t.Errorf("CFB round trip = %x, want %x", got, pt)
+	}
+}
+
+// meshReference returns the cipher under K' = D_K(C), where C is the key
+// meshing constant of RFC 4357, section 2.3.2.
+func meshReference(t *testing.T, ref *referenceCipher) *referenceCipher {
+	constant := decodeHex(t, "6900722264c904238d3adb9646e92ac418feac9400ed0712c086dcc2ef4ca92b")
+	key := make([]byte, KeySize)
+	for i := 0; i < KeySize; i += BlockSize {
+		ref.Decrypt(key[i:], constant[i:])
+	}
+	return newReferenceCipher(key, ref.sbox)
+}
+
+func TestKeyMeshing(t *testing.T) {
+	c, _ := NewCipher(testKey(), SBoxCryptoProA)
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pt := testData(3*meshingPeriod + 11)
+	blocksPerKey := meshingPeriod / BlockSize
+
+	// The expected output below is built with the reference cipher from
+	// the description of key meshing; the vectors at the end of the test
+	// come from outside implementations.
+	//
+	// Gamma mode: after every 1024 bytes the key becomes K' and N3, N4
+	// are replaced by their encryption under K'.
+	var wantCTR []byte
+	ref := newReferenceCipher(testKey(), SBoxCryptoProA)
+	s := make([]byte, BlockSize)
+	ref.Encrypt(s, iv)
+	for i := 0; len(wantCTR) < len(pt); i++ {
+		if i > 0 && i%blocksPerKey == 0 {
+			ref = meshReference(t, ref)
+			ref.Encrypt(s, s)
+		}
+		n3 := (uint64(binary.LittleEndian.Uint32(s[:4])) + c2) % (1 << 32)
+		n4 := (uint64(binary.LittleEndian.Uint32(s[4:])) + c1) % (1<<32 - 1)
+		binary.LittleEndian.PutUint32(s[:4], uint32(n3))
+		binary.LittleEndian.PutUint32(s[4:], uint32(n4))
+		g := make([]byte, BlockSize)
+		ref.Encrypt(g, s)
+		wantCTR = append(wantCTR, g...)
+	}
+	for i := range pt {
+		wantCTR[i] ^= pt[i]
+	}
+	wantCTR = wantCTR[:len(pt)]
+
+	// Gamma with feedback: after every 1024 bytes the key becomes K' and
+	// the last ciphertext block is encrypted under K' before it is fed
+	// back.
+	wantCFB := make([]byte, len(pt))
+	ref = newReferenceCipher(testKey(), SBoxCryptoProA)
+	reg := append([]byte(nil), iv...)
+	g := make([]byte, BlockSize)
+	for i := 0; i*BlockSize < len(pt); i++ {
+		if i > 0 && i%blocksPerKey == 0 {
+			ref = meshReference(t, ref)
+			ref.Encrypt(reg, reg)
+		}
+		ref.Encrypt(g, reg)
+		for j := 0; j < BlockSize && i*BlockSize+j < len(pt); j++ {
+			wantCFB[i*BlockSize+j] = pt[i*BlockSize+j] ^ g[j]
+		}
+		copy(reg, wantCFB[i*BlockSize:])
+	}
+
+	tests := []struct {
+		name    string
+		want    []byte
+		meshed  cipher.Stream
+		inverse cipher.Stream
+	}{
+		{"CTR", wantCTR, NewCTRWithKeyMeshing(c, iv), NewCTRWithKeyMeshing(c, iv)},
+		{"CFB", wantCFB, NewCFBEncrypterWithKeyMeshing(c, iv), NewCFBDecrypterWithKeyMeshing(c, iv)},
+	}
+	for _, test := range tests {
+		meshed := make([]byte, len(pt))
+		test.meshed.XORKeyStream(meshed[:meshingPeriod-3], pt[:meshingPeriod-3])
+		test.meshed.XORKeyStream(meshed[meshingPeriod-3:], pt[meshingPeriod-3:])
+
+		for i := 0; i < len(pt); i += meshingPeriod {
+			end := i + meshingPeriod
+			if end > len(pt) {
+				end = len(pt)
+			}
+			if !bytes.Equal(meshed[i:end], test.want[i:end]) {
+				t.Errorf("%s: bytes %d..%d = %x, want %x", test.name, i, end-1, meshed[i:end], test.want[i:end])
+			}
+		}
+
+		got := make([]byte, len(pt))
+		test.inverse.XORKeyStream(got, meshed)
+		if !bytes.Equal(got, pt) {
+			t.Errorf("%s: round trip with meshing failed", test.name)
+		}
+	}
+
+	// Output for the same key, IV and data around the first two
+	// rekeyings. The gamma bytes are from the CNT mode of GnuTLS 3.7.9,
+	// the CFB bytes from GnuTLS 3.7.9 and libgcrypt 1.10.1, which agree.
+	vectors := []struct {
+		name   string
+		stream cipher.Stream
+		offset int
+		want   string
+	}{
+		{"CTR", NewCTRWithKeyMeshing(c, iv), 1016, "25272a72d394fef612da168ff4b51ae6b411df716f83b5ab251b3fc063fa04c0"},
+		{"CTR", NewCTRWithKeyMeshing(c, iv), 2040, "f2bb3a4b285d6ba567cd319c82003f30cb5f6fa91448df9be37584926820e31e"},
+		{"CFB", NewCFBEncrypterWithKeyMeshing(c, iv), 1016, "b7a71c52338d6cc08592538b54a6171da75b0eeb0361e4a3266e7e73faac20e2"},
+		{"CFB", NewCFBEncrypterWithKeyMeshing(c, iv), 2040, "46aeb0900c397e568bc2763a2a287c404e7e0383cc823db7df7616b041bb5446"},
+	}
+	for _, v := range vectors {
+		want := decodeHex(t, v.want)
+		got := make([]byte, v.offset+len(want))
+		v.stream.XORKeyStream(got, pt[:len(got)])
+		if !bytes.Equal(got[v.offset:], want) {
+			t.Errorf("%s: bytes %d..%d = %x, want %s", v.name, v.offset, len(got)-1, got[v.offset:], v.want)
+		}
+	}
+
+	// The streams mesh private copies of the cipher.
+	again := make([]byte, BlockSize)
+	c.Encrypt(again, iv)
+	fresh, _ := NewCipher(testKey(), SBoxCryptoProA)
+	want := make([]byte, BlockSize)
+	fresh.Encrypt(want, iv)
+	if !bytes.Equal(again, want) {
+		t.Error("key meshing changed the caller's cipher")
+	}
+}
+
+func TestMAC(t *testing.T) {
+	key := testKey()
+	data := testData(3*BlockSize + 3)
+
+	m, err := NewMAC(key, SBoxCryptoProA, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Write(data[:1])
+	m.Write(data[1:])
+	sum := m.Sum(nil)
+	if !bytes.Equal(m.Sum(nil), sum) {
+		t.Error("Sum changed the state")
+	}
+
+	// A short final block is padded with zeroes.
+	padded := append(append([]byte(nil), data...), make([]byte, BlockSize-3)...)
+	m.Reset()
+	m.Write(padded)
+	if got := m.Sum(nil); !bytes.Equal(got, sum) {
+		t.Errorf("MAC of zero-padded data = %x, want %x", got, sum)
+	}
+
+	// A single block is followed by a zero block.
+	m.Reset()
+	m.Write(data[:BlockSize])
+	one := m.Sum(nil)
+	m.Reset()
+	m.Write(append(append([]byte(nil), data[:BlockSize]...), make([]byte, BlockSize)...))
+	if two := m.Sum(nil); !bytes.Equal(one, two) {
+		t.Errorf("MAC of one block = %x, want %x", one, two)
+	}
+
+	// Shorter MACs are the low bits of the 64-bit one.
+	for _, size := range []int{1, 13, 32, 63} {
+		short, err := NewMAC(key, SBoxCryptoProA, size, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		short.Write(data)
+		got := short.Sum(nil)
+		want := append([]byte(nil), sum[:short.Size()]...)
+		if rem := size % 8; rem != 0 {
+			want[len(want)-1] &= 1<<rem - 1
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%d-bit MAC = %x, want %x", size, got, want)
+		}
+		if !short.Verify(want) {
+			t.Errorf("%d-bit MAC does not verify", size)
+		}
+		want[0] ^= 1
+		if short.Verify(want) {
+			t.Errorf("%d-bit MAC verifies a wrong tag", size)
+		}
+	}
+
+	for _, size := range []int{0, 65} {
+		if _, err := NewMAC(key, nil, size, nil); err == nil {
+			t.Errorf("NewMAC accepted size %d", size)
+		}
+	}
+}
+
+// referenceMAC computes the 64-bit imitovstavka with referenceCipher from
+// the definition: zero padding, at least two blocks, and the 16-round
+// cycle over the sum of every block with the state.
+func referenceMAC(key []byte, sbox *SBox, iv uint64, data []byte) uint64 {
+	ref := newReferenceCipher(key, sbox)
+	padded := append([]byte(nil), data...)
+	for len(padded)%BlockSize != 0 {
+		padded = append(padded, 0)
+	}
+	if len(padded) == BlockSize {
+		padded = append(padded, make([]byte, BlockSize)...)
+	}
+	s := iv
+	for i := 0; i < len(padded); i += BlockSize {
+		s = ref.mac16(s ^ binary.LittleEndian.Uint64(padded[i:]))
+	}
+	return s
+}
+
+func TestMACKnownAnswer(t *testing.T) {
+	// 32-bit MACs under the CryptoPro-A S-box with a zero IV, computed
+	// with GnuTLS 3.7.9 and with libgcrypt 1.10.1, which agree. Sum
+	// returns N1 in little-endian order.
+	key := []byte("This is message\xFF length=32 bytes")
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"a", "3a492e9f"},
+		{"abc", "17c34909"},
+		{"12345678", "1c0f2a71"},
+		{"This is message\xFF length=32 bytes", "5a826060"},
+	}
+	for _, test := range tests {
+		want := decodeHex(t, test.want)
+		var ref [BlockSize]byte
+		binary.LittleEndian.PutUint64(ref[:], referenceMAC(key, SBoxCryptoProA, 0, []byte(test.data)))
+		if !bytes.Equal(ref[:4], want) {
+			t.Fatalf("referenceMAC(%q) = %x, want %s", test.data, ref[:4], test.want)
+		}
+
+		m, err := NewMAC(key, SBoxCryptoProA, 32, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Write([]byte(test.data))
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("MAC(%q) = %x, want %s", test.data, got, test.want)
+		}
+	}
+
+	// A non-zero IV and longer data against the reference alone.
+	iv := testData(BlockSize)
+	data := testData(5*BlockSize + 3)
+	m, _ := NewMAC(testKey(), SBoxCryptoProA, 64, iv)
+	m.Write(data)
+	want := make([]byte, BlockSize)
+	binary.LittleEndian.PutUint64(want, referenceMAC(testKey(), SBoxCryptoProA, binary.LittleEndian.Uint64(iv), data))
+	if got := m.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("MAC with IV = %x, want %x", got, want)
+	}
+}
+
+func TestKeyWrap(t *testing.T) {
+	kek := testKey()
+	cek := testData(KeySize)
+	ukm := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		name   string
+		wrap   func(kek, ukm, cek []byte, sbox *SBox) ([]byte, error)
+		unwrap func(kek, wrapped []byte, sbox *SBox) ([]byte, error)
+	}{
+		{"GOST", Wrap, Unwrap},
+		{"CryptoPro", WrapCryptoPro, UnwrapCryptoPro},
+	}
+	for _, test := range tests {
+		wrapped, err := test.wrap(kek, ukm, cek, SBoxCryptoProA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(wrapped) != WrappedKeySize || !bytes.Equal(wrapped[:UKMSize], ukm) {
+			t.Fatalf("%s: wrapped key %x", test.name, wrapped)
+		}
+
+		got, err := test.unwrap(kek, wrapped, SBoxCryptoProA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, cek) {
+			t.Errorf("%s: Unwrap = %x, want %x", test.name, got, cek)
+		}
+
+		for _, i := range []int{0, UKMSize, WrappedKeySize - 1} {
+			bad := append([]byte(nil), wrapped...)
+			bad[i] ^= 0x80
+			if _, err := test.unwrap(kek, bad, SBoxCryptoProA); err != ErrUnwrap {
+				t.Errorf("%s: Unwrap with byte %d flipped: err = %v, want ErrUnwrap", test.name, i, err)
+			}
+		}
+	}
+
+	plain, _ := Wrap(kek, ukm, cek, SBoxCryptoProA)
+	cryptoPro, _ := WrapCryptoPro(kek, ukm, cek, SBoxCryptoProA)
+	if bytes.Equal(plain, cryptoPro) {
+		t.Error("CryptoPro key wrap did not diversify the KEK")
+	}
+}
+
+// referenceDiversify is the KEK diversification of RFC 4357, section
+// 6.5, with the reference cipher and crypto/cipher's CFB. Bit j of UKM
+// byte i selects whether key word j goes into s1 or s2 in round i.
+func referenceDiversify(kek, ukm []byte, sbox *SBox) []byte {
+	k := append([]byte(nil), kek...)
+	for i := 0; i < UKMSize; i++ {
+		var s1, s2 uint64
+		for j := 0; j < 8; j++ {
+			w := uint64(binary.LittleEndian.Uint32(k[4*j:]))
+			if ukm[i]&(1<<j) != 0 {
+				s1 = (s1 + w) % (1 << 32)
+			} else {
+				s2 = (s2 + w) % (1 << 32)
+			}
+		}
+		iv := make([]byte, BlockSize)
+		binary.LittleEndian.PutUint32(iv[:4], uint32(s1))
+		binary.LittleEndian.PutUint32(iv[4:], uint32(s2))
+		cipher.NewCFBEncrypter(newReferenceCipher(k, sbox), iv).XORKeyStream(k, k)
+	}
+	return k
+}
+
+func TestKeyWrapKnownAnswer(t *testing.T) {
+	kek := testKey()
+	cek := testData(KeySize)
+	ukm := []byte{0x01, 0x80, 0xff, 0x00, 0x5a, 0xa5, 0x3c, 0x0f}
+
+	kekUKM, err := DiversifyKEK(kek, ukm, SBoxCryptoProA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := referenceDiversify(kek, ukm, SBoxCryptoProA); !bytes.Equal(kekUKM, want) {
+		t.Errorf("DiversifyKEK = %x, want %x", kekUKM, want)
+	}
+	// The same diversification with the CryptoPro KDF of GnuTLS 3.7.9.
+	if want := decodeHex(t, "e20c1bd07759997586941a34fcd553ae7660330ea424d3eaf29c841150264913"); !bytes.Equal(kekUKM, want) {
+		t.Errorf("DiversifyKEK = %x, want %x", kekUKM, want)
+	}
+
+	// The wrapped key from its definition: UKM, the CEK encrypted in
+	// simple replacement mode and the low 32 bits of its MAC with the UKM
+	// as the initial state, all under the diversified KEK.
+	ref := newReferenceCipher(kekUKM, SBoxCryptoProA)
+	want := append([]byte(nil), ukm...)
+	for i := 0; i < KeySize; i += BlockSize {
+		block := make([]byte, BlockSize)
+		ref.Encrypt(block, cek[i:])
+		want = append(want, block...)
+	}
+	mac := make([]byte, BlockSize)
+	binary.LittleEndian.PutUint64(mac, referenceMAC(kekUKM, SBoxCryptoProA, binary.LittleEndian.Uint64(ukm), cek))
+	want = append(want, mac[:4]...)
+
+	got, err := WrapCryptoPro(kek, ukm, cek, SBoxCryptoProA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("WrapCryptoPro = %x, want %x", got, want)
+	}
+
+	// The encrypted key and MAC from the CryptoPro key wrap of GnuTLS
+	// 3.7.9 for the same KEK, UKM and CEK.
+	external := decodeHex(t, "0180ff005aa53c0f"+
+		"5b62dff5f7300d48173bea0ad347dfc1b956d9f73006dce0bd5bd434ce855db0"+"c55cc830")
+	if !bytes.Equal(got, external) {
+		t.Errorf("WrapCryptoPro = %x, want %x", got, external)
+	}
+}
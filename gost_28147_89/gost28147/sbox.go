@@ -0,0 +1,190 @@
+package gost28147
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SBox is a set of eight 4-bit substitution tables. Row i is applied
+// to the i-th nibble of the round input, counting from the least
+// significant one.
+type SBox [8][16]uint8
+
+// SBoxTest is the id-Gost28147-89-TestParamSet S-box from
+// GOST R 34.11-94.
+var SBoxTest = &SBox{
+	{4, 10, 9, 2, 13, 8, 0, 14, 6, 11, 1, 12, 7, 15, 5, 3},
+	{14, 11, 4, 12, 6, 13, 15, 10, 2, 3, 8, 1, 0, 7, 5, 9},
+	{5, 8, 1, 13, 10, 3, 4, 2, 14, 15, 12, 7, 6, 0, 9, 11},
+	{7, 13, 10, 1, 0, 8, 9, 15, 14, 4, 6, 12, 11, 2, 5, 3},
+	{6, 12, 7, 1, 5, 15, 13, 8, 4, 10, 9, 14, 0, 3, 11, 2},
+	{4, 11, 10, 0, 7, 2, 1, 13, 3, 6, 8, 5, 9, 12, 15, 14},
+	{13, 11, 4, 1, 3, 15, 5, 9, 0, 10, 14, 7, 6, 8, 2, 12},
+	{1, 15, 13, 0, 5, 7, 10, 4, 9, 2, 3, 14, 6, 11, 8, 12},
+}
+
+// SBoxCryptoProA is id-Gost28147-89-CryptoPro-A-ParamSet from RFC 4357.
+var SBoxCryptoProA = &SBox{
+	{9, 6, 3, 2, 8, 11, 1, 7, 10, 4, 14, 15, 12, 0, 13, 5},
+	{3, 7, 14, 9, 8, 10, 15, 0, 5, 2, 6, 12, 11, 4, 13, 1},
+	{14, 4, 6, 2, 11, 3, 13, 8, 12, 15, 5, 10, 0, 7, 1, 9},
+	{14, 7, 10, 12, 13, 1, 3, 9, 0, 2, 11, 4, 15, 8, 5, 6},
+	{11, 5, 1, 9, 8, 13, 15, 0, 14, 4, 2, 3, 12, 7, 10, 6},
+	{3, 10, 13, 12, 1, 2, 0, 11, 7, 5, 9, 4, 8, 15, 14, 6},
+	{1, 13, 2, 9, 7, 10, 6, 0, 8, 12, 4, 5, 15, 3, 11, 14},
+	{11, 10, 15, 5, 0, 12, 14, 8, 6, 2, 3, 9, 1, 7, 13, 4},
+}
+
+// SBoxCryptoProB is id-Gost28147-89-CryptoPro-B-ParamSet from RFC 4357.
+var SBoxCryptoProB = &SBox{
+	{8, 4, 11, 1, 3, 5, 0, 9, 2, 14, 10, 12, 13, 6, 7, 15},
+	{0, 1, 2, 10, 4, 13, 5, 12, 9, 7, 3, 15, 11, 8, 6, 14},
+	{14, 12, 0, 10, 9, 2, 13, 11, 7, 5, 8, 15, 3, 6, 1, 4},
+	{7, 5, 0, 13, 11, 6, 1, 2, 3, 10, 12, 15, 4, 14, 9, 8},
+	{2, 7, 12, 15, 9, 5, 10, 11, 1, 4, 0, 13, 6, 8, 14, 3},
+	{8, 3, 2, 6, 4, 13, 14, 11, 12, 1, 7, 15, 10, 0, 9, 5},
+	{5, 2, 10, 11, 9, 1, 12, 3, 7, 4, 13, 0, 6, 15, 8, 14},
+	{0, 4, 11, 14, 8, 3, 7, 1, 10, 2, 9, 6, 15, 13, 5, 12},
+}
+
+// SBoxCryptoProC is id-Gost28147-89-CryptoPro-C-ParamSet from RFC 4357.
+var SBoxCryptoProC = &SBox{
+	{1, 11, 12, 2, 9, 13, 0, 15, 4, 5, 8, 14, 10, 7, 6, 3},
+	{0, 1, 7, 13, 11, 4, 5, 2, 8, 14, 15, 12, 9, 10, 6, 3},
+	{8, 2, 5, 0, 4, 9, 15, 10, 3, 7, 12, 13, 6, 14, 1, 11},
+	{3, 6, 0, 1, 5, 13, 10, 8, 11, 2, 9, 7, 14, 15, 12, 4},
+	{8, 13, 11, 0, 4, 5, 1, 2, 9, 3, 12, 14, 6, 15, 10, 7},
+	{12, 9, 11, 1, 8, 14, 2, 4, 7, 3, 6, 5, 10, 0, 15, 13},
+	{10, 9, 6, 8, 13, 14, 2, 0, 15, 3, 5, 11, 4, 1, 12, 7},
+	{7, 4, 0, 5, 10, 2, 15, 14, 12, 6, 1, 11, 13, 9, 3, 8},
+}
+
+// SBoxCryptoProD is id-Gost28147-89-CryptoPro-D-ParamSet from RFC 4357.
+var SBoxCryptoProD = &SBox{
+	{15, 12, 2, 10, 6, 4, 5, 0, 7, 9, 14, 13, 1, 11, 8, 3},
+	{11, 6, 3, 4, 12, 15, 14, 2, 7, 13, 8, 0, 5, 10, 9, 1},
+	{1, 12, 11, 0, 15, 14, 6, 5, 10, 13, 4, 8, 9, 3, 7, 2},
+	{1, 5, 14, 12, 10, 7, 0, 13, 6, 2, 11, 4, 9, 3, 15, 8},
+	{0, 12, 8, 9, 13, 2, 10, 11, 7, 3, 6, 5, 4, 14, 15, 1},
+	{8, 0, 15, 3, 2, 5, 14, 11, 1, 10, 4, 7, 12, 9, 13, 6},
+	{3, 0, 6, 15, 1, 14, 9, 2, 13, 8, 12, 4, 11, 10, 5, 7},
+	{1, 10, 6, 8, 15, 11, 0, 4, 12, 3, 5, 9, 7, 13, 2, 14},
+}
+
+// SBoxTC26Z is id-tc26-gost-28147-param-Z from RFC 7836, the S-box of
+// GOST R 34.12-2015 Magma.
+var SBoxTC26Z = &SBox{
+	{12, 4, 6, 2, 10, 5, 11, 9, 14, 8, 13, 7, 0, 3, 15, 1},
+	{6, 8, 2, 3, 9, 10, 5, 12, 1, 14, 4, 7, 11, 13, 0, 15},
+	{11, 3, 5, 8, 2, 15, 10, 13, 14, 1, 7, 4, 12, 9, 6, 0},
+	{12, 8, 2, 1, 13, 4, 15, 6, 7, 0, 10, 5, 3, 14, 9, 11},
+	{7, 15, 5, 10, 8, 1, 6, 13, 0, 9, 3, 14, 11, 4, 2, 12},
+	{5, 13, 15, 6, 9, 2, 12, 10, 11, 7, 8, 1, 4, 3, 14, 0},
+	{8, 14, 2, 5, 6, 9, 1, 12, 15, 4, 11, 0, 13, 10, 3, 7},
+	{1, 7, 14, 13, 0, 5, 8, 3, 4, 15, 10, 6, 9, 12, 11, 2},
+}
+
+// ParamSet names a built-in S-box.
+type ParamSet struct {
+	Name string
+	OID  string
+	SBox *SBox
+}
+
+// ParamSets lists the built-in S-boxes.
+var ParamSets = []ParamSet{
+	{"id-Gost28147-89-TestParamSet", "1.2.643.2.2.31.0", SBoxTest},
+	{"id-Gost28147-89-CryptoPro-A-ParamSet", "1.2.643.2.2.31.1", SBoxCryptoProA},
+	{"id-Gost28147-89-CryptoPro-B-ParamSet", "1.2.643.2.2.31.2", SBoxCryptoProB},
+	{"id-Gost28147-89-CryptoPro-C-ParamSet", "1.2.643.2.2.31.3", SBoxCryptoProC},
+	{"id-Gost28147-89-CryptoPro-D-ParamSet", "1.2.643.2.2.31.4", SBoxCryptoProD},
+	{"id-tc26-gost-28147-param-Z", "1.2.643.7.1.2.5.1.1", SBoxTC26Z},
+}
+
+// LookupSBox returns the built-in S-box with the given name or OID.
+// Names are matched case-insensitively.
+func LookupSBox(nameOrOID string) (*SBox, error) {
+	for _, p := range ParamSets {
+		if p.OID == nameOrOID || strings.EqualFold(p.Name, nameOrOID) {
+			return p.SBox, nil
+		}
+	}
+	return nil, fmt.Errorf("gost28147: unknown parameter set %q", nameOrOID)
+}
+
+// Validate checks that every row of the S-box is a permutation of 0..15.
+func (s *SBox) Validate() error {
+	for i, row := range s {
+		var seen [16]bool
+		for _, v := range row {
+			if v > 15 || seen[v] {
+				return fmt.Errorf("gost28147: S-box row %d is not a permutation of 0..15", i)
+			}
+			seen[v] = true
+		}
+	}
+	return nil
+}
+
+// ReadSBox parses an S-box of eight rows with sixteen values each. Values
+// are separated by spaces or commas and may be written in decimal or,
+// with a 0x prefix, in hex. Blank lines and lines starting with # are
+// skipped.
+func ReadSBox(r io.Reader) (*SBox, error) {
+	var s SBox
+	rows := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rows == len(s) {
+			return nil, errors.New("gost28147: S-box has more than 8 rows")
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(fields) != len(s[rows]) {
+			return nil, fmt.Errorf("gost28147: S-box row %d has %d values, want 16", rows, len(fields))
+		}
+		for j, f := range fields {
+			v, err := strconv.ParseUint(f, 0, 8)
+			if err != nil {
+				return nil, fmt.Errorf("gost28147: S-box row %d: %w", rows, err)
+			}
+			s[rows][j] = uint8(v)
+		}
+		rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rows != len(s) {
+		return nil, fmt.Errorf("gost28147: S-box has %d rows, want 8", rows)
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// LoadSBox reads an S-box from the named file, see ReadSBox.
+func LoadSBox(path string) (*SBox, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadSBox(f)
+}
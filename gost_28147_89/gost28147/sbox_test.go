@@ -0,0 +1,109 @@
+package gost28147
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// formatSBox writes s in the format ReadSBox accepts, with a comment and
+// a blank line in front and mixed separators.
+func formatSBox(s *SBox) string {
+	var b strings.Builder
+	b.WriteString("# test S-box\n\n")
+	for _, row := range s {
+		for j, v := range row {
+			switch {
+			case j == 0:
+			case j%2 == 0:
+				b.WriteString(", ")
+			default:
+				b.WriteString("\t")
+			}
+			fmt.Fprintf(&b, "%#x", v)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestParamSets(t *testing.T) {
+	for _, p := range ParamSets {
+		if err := p.SBox.Validate(); err != nil {
+			t.Errorf("%s: %v", p.Name, err)
+		}
+		for _, key := range []string{p.Name, strings.ToUpper(p.Name), p.OID} {
+			if s, err := LookupSBox(key); err != nil || s != p.SBox {
+				t.Errorf("LookupSBox(%q) = %p, %v, want %p", key, s, err, p.SBox)
+			}
+		}
+	}
+	if _, err := LookupSBox("1.2.643.2.2.31.9"); err == nil {
+		t.Error("LookupSBox accepted an unknown OID")
+	}
+}
+
+func TestReadSBox(t *testing.T) {
+	s, err := ReadSBox(strings.NewReader(formatSBox(SBoxCryptoProB)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s != *SBoxCryptoProB {
+		t.Errorf("ReadSBox = %v, want %v", s, SBoxCryptoProB)
+	}
+
+	valid := strings.Split(strings.TrimSpace(formatSBox(SBoxTest)), "\n")
+	swapped := *SBoxTest
+	swapped[3][0] = swapped[3][1]
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"seven rows", strings.Join(valid[:len(valid)-1], "\n")},
+		{"nine rows", strings.Join(append(valid, valid[len(valid)-1]), "\n")},
+		{"short row", strings.Join(valid, "\n") + ", 0"},
+		{"not a number", strings.Replace(strings.Join(valid, "\n"), "0x4", "four", 1)},
+		{"value above 255", strings.Replace(strings.Join(valid, "\n"), "0x4", "0x104", 1)},
+		{"value above 15", strings.Replace(strings.Join(valid, "\n"), "0x4", "0x10", 1)},
+		{"non-permutation row", formatSBox(&swapped)},
+	}
+	for _, test := range tests {
+		if s, err := ReadSBox(strings.NewReader(test.text)); err == nil {
+			t.Errorf("%s: ReadSBox = %v, want error", test.name, s)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := *SBoxTC26Z
+	s[7][15] = s[7][0]
+	if err := s.Validate(); err == nil {
+		t.Error("Validate accepted a repeated value")
+	}
+	s = *SBoxTC26Z
+	s[0][4] = 16
+	if err := s.Validate(); err == nil {
+		t.Error("Validate accepted a value above 15")
+	}
+}
+
+func TestLoadSBox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sbox.txt")
+	if err := os.WriteFile(path, []byte(formatSBox(SBoxCryptoProD)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSBox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s != *SBoxCryptoProD {
+		t.Errorf("LoadSBox = %v, want %v", s, SBoxCryptoProD)
+	}
+
+	if _, err := LoadSBox(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadSBox of a missing file did not fail")
+	}
+}
@@ -2,181 +2,162 @@ package main
 
 import (
 	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"io"
 	"log"
 	"os"
+
+	"six_nine/gost_28147_89/gost28147"
 )
 
 var K = []uint32{1, 5, 8783244, 7263234, 123124545, 13, 69, 228}
-var H = [][]uint32{
-	{4, 10, 9, 2, 13, 8, 0, 14, 6, 11, 1, 12, 7, 15, 5, 3},
-	{14, 11, 4, 12, 6, 13, 15, 10, 2, 3, 8, 1, 0, 7, 5, 9},
-	{5, 8, 1, 13, 10, 3, 4, 2, 14, 15, 12, 7, 6, 0, 9, 11},
-	{7, 13, 10, 1, 0, 8, 9, 15, 14, 4, 6, 12, 11, 2, 5, 3},
-	{6, 12, 7, 1, 5, 15, 13, 8, 4, 10, 9, 14, 0, 3, 11, 2},
-	{4, 11, 10, 0, 7, 2, 1, 13, 3, 6, 8, 5, 9, 12, 15, 14},
-	{13, 11, 4, 1, 3, 15, 5, 9, 0, 10, 14, 7, 6, 8, 2, 12},
-	{1, 15, 13, 0, 5, 7, 10, 4, 9, 2, 3, 14, 6, 11, 8, 12},
-} // no int4, just ignoring other bits
-
-func mainStep(n uint64, x uint32) uint64 {
-	n1 := uint32(n)
-	n2 := uint32(n >> 32)
-
-	var s uint32 = n1 + x
-	var sn uint32 = 0
-	for i := 0; i < 8; i++ {
-		var si uint32 = (s >> (4 * i)) & 0xF
-		si = H[i][si]
-		sn |= si << (4 * i)
-	}
-
-	sn <<= 11
-
-	sn ^= n2
 
-	n2 = n1
-	n1 = sn
+func keyBytes(k []uint32) []byte {
+	key := make([]byte, 4*len(k))
+	for i, x := range k {
+		binary.LittleEndian.PutUint32(key[4*i:], x)
+	}
 
-	return (uint64(n1) << 32) | uint64(n2)
+	return key
 }
 
-func swapHalfs(n uint64) uint64 {
-	return (n << 32) | (n >> 32)
+func cryptECB(mode cipher.BlockMode, r io.Reader, w io.Writer) error {
+	buf := make([]byte, 512*gost28147.BlockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n%gost28147.BlockSize != 0 {
+			return errors.New("simple replacement mode needs whole 8-byte blocks")
+		}
+		mode.CryptBlocks(buf[:n], buf[:n])
+		if _, werr := w.Write(buf[:n]); werr != nil {
+			return werr
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
 }
 
-func encode32cycle(n uint64) uint64 {
-	for k := 1; k <= 3; k++ {
-		for j := 0; j < 8; j++ {
-			n = mainStep(n, K[j])
+func main() {
+	inputFileName := flag.String("in", "input.txt", "file to read")
+	outputFileName := flag.String("out", "output.txt", "file to write")
+	mode := flag.String("mode", "cnt", "ecb (simple replacement), cnt (gamma) or cfb (gamma with feedback)")
+	keyHex := flag.String("key", "", "256-bit key in hex")
+	ivHex := flag.String("iv", "", "64-bit synchronization message in hex")
+	decrypt := flag.Bool("d", false, "decrypt instead of encrypt")
+	macBits := flag.Int("mac", 32, "imitovstavka length in bits, 0 to skip it")
+	sboxName := flag.String("sbox", "id-Gost28147-89-TestParamSet", "name or OID of a built-in S-box")
+	sboxFile := flag.String("sbox-file", "", "file to load a custom S-box from")
+	meshing := flag.Bool("mesh", false, "apply CryptoPro key meshing in cnt and cfb modes")
+	flag.Parse()
+
+	var err error
+
+	key := keyBytes(K)
+	if *keyHex != "" {
+		if key, err = hex.DecodeString(*keyHex); err != nil {
+			log.Fatal(err)
 		}
 	}
 
-	for j := 0; j < 8; j++ {
-		n = mainStep(n, K[j])
+	var sbox *gost28147.SBox
+	if *sboxFile != "" {
+		sbox, err = gost28147.LoadSBox(*sboxFile)
+	} else {
+		sbox, err = gost28147.LookupSBox(*sboxName)
 	}
-
-	n = swapHalfs(n)
-
-	return n
-}
-
-func decode32cycle(n uint64) uint64 {
-
-	for j := 0; j < 8; j++ {
-		n = mainStep(n, K[j])
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	for k := 1; k <= 3; k++ {
-		for j := 0; j < 8; j++ {
-			n = mainStep(n, K[j])
-		}
+	block, err := gost28147.NewCipher(key, sbox)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	n = swapHalfs(n)
-
-	return n
-}
-
-func mac16cycle(n uint64) uint64 {
-	for k := 1; k <= 2; k++ {
-		for j := 0; j < 8; j++ {
-			n = mainStep(n, K[j])
+	var iv []byte
+	if *mode != "ecb" {
+		if iv, err = hex.DecodeString(*ivHex); err != nil {
+			log.Fatal(err)
+		}
+		if len(iv) != gost28147.BlockSize {
+			log.Fatal("synchronization message must be 8 bytes long")
 		}
 	}
 
-	return n
-}
-
-func macCycle(t []uint64) uint32 {
-	var s uint64 = 0
-	for i := 0; i < len(t); i++ {
-		s = mac16cycle(s ^ t[i])
+	inFile, err := os.Open(*inputFileName)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	return uint32(s)
-}
-
-func main() {
-	const inputFileName = "input.txt"
-	const outputFileName = "output.txt"
-	const space = ' '
-
-	inFile, err := os.Open(inputFileName)
 	defer inFile.Close()
 
+	outFile, err := os.Create(*outputFileName)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer outFile.Close()
 
-	reader := bufio.NewReader(inFile)
-	var buf uint64 = 0
-	var numOfByte = 0
-	var data []uint64
-	for {
-		if char, err := reader.ReadByte(); err != nil {
-			if err == io.EOF {
-				break
-			} else {
-				log.Fatal(err)
-			}
+	var reader io.Reader = bufio.NewReader(inFile)
+	bufWriter := bufio.NewWriter(outFile)
+	var writer io.Writer = bufWriter
+
+	var mac *gost28147.MAC
+	if *macBits != 0 {
+		if mac, err = gost28147.NewMAC(key, sbox, *macBits, nil); err != nil {
+			log.Fatal(err)
+		}
+		if *decrypt {
+			writer = io.MultiWriter(writer, mac)
 		} else {
-			buf |= uint64(char) << (8 * numOfByte)
-			numOfByte++
-			if numOfByte >= 8 {
-				data = append(data, buf)
-				buf = 0
-				numOfByte = 0
-			}
+			reader = io.TeeReader(reader, mac)
 		}
 	}
 
-	if numOfByte > 0 {
-		for i := numOfByte; i < 8; i++ {
-			buf |= uint64(space) << (8 * numOfByte)
-			numOfByte++
+	switch *mode {
+	case "ecb":
+		var bm cipher.BlockMode
+		if *decrypt {
+			bm = gost28147.NewECBDecrypter(block)
+		} else {
+			bm = gost28147.NewECBEncrypter(block)
 		}
-
-		data = append(data, buf)
-	}
-
-	var dataMac = macCycle(data)
-	log.Println("Data MAC is ", dataMac)
-
-	var encodedData []uint64
-	for i := 0; i < len(data); i++ {
-		encodedData = append(encodedData, encode32cycle(data[i]))
-	}
-
-	var decodedData []uint64
-	for i := 0; i < len(data); i++ {
-		decodedData = append(decodedData, decode32cycle(encodedData[i]))
+		err = cryptECB(bm, reader, writer)
+	case "cnt", "cfb":
+		var stream cipher.Stream
+		switch {
+		case *mode == "cnt" && *meshing:
+			stream = gost28147.NewCTRWithKeyMeshing(block, iv)
+		case *mode == "cnt":
+			stream = gost28147.NewCTR(block, iv)
+		case *decrypt && *meshing:
+			stream = gost28147.NewCFBDecrypterWithKeyMeshing(block, iv)
+		case *decrypt:
+			stream = gost28147.NewCFBDecrypter(block, iv)
+		case *meshing:
+			stream = gost28147.NewCFBEncrypterWithKeyMeshing(block, iv)
+		default:
+			stream = gost28147.NewCFBEncrypter(block, iv)
+		}
+		_, err = io.Copy(cipher.StreamWriter{S: stream, W: writer}, reader)
+	default:
+		log.Fatalf("unknown mode %q", *mode)
 	}
-
-	log.Println(data)
-	log.Println(decodedData)
-
-	var decodedDataMac = macCycle(decodedData)
-	log.Println("Decoded data MAC is ", decodedDataMac)
-
-	outFile, err := os.Create(outputFileName)
-	defer outFile.Close()
-
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	writer := bufio.NewWriter(outFile)
-
-	for i := 0; i < len(decodedData); i++ {
-		for byteNum := 0; byteNum < 8; byteNum++ {
-			b := byte(decodedData[i] >> (uint64(byteNum) * 8))
-			err := writer.WriteByte(b)
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
+	if err := bufWriter.Flush(); err != nil {
+		log.Fatal(err)
 	}
-	writer.Flush()
 
+	if mac != nil {
+		log.Println("Data MAC is", hex.EncodeToString(mac.Sum(nil)))
+	}
 }
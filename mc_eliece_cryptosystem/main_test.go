@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// The matrices are the worked example from the Wikipedia article on the
+// McEliece cryptosystem.
+
+func TestKeyGen(t *testing.T) {
+	want := []uint8{0b1111000, 0b1100100, 0b1001101, 0b0101110}
+	sgp := keyGen()
+	if len(sgp) != len(want) {
+		t.Fatalf("keyGen() has %d rows, want %d", len(sgp), len(want))
+	}
+	for i := range want {
+		if sgp[i] != want[i] {
+			t.Errorf("row %d = %07b, want %07b", i, sgp[i], want[i])
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		ciphertext uint8
+		want       uint8
+	}{
+		{0b0110010, 0b1101},
+		{0b0110110, 0b1101},
+	}
+	for _, test := range tests {
+		if got := decode(test.ciphertext); got != test.want {
+			t.Errorf("decode(%07b) = %04b, want %04b", test.ciphertext, got, test.want)
+		}
+	}
+}
+
+func TestEveryErrorIsCorrected(t *testing.T) {
+	sgp := keyGen()
+	sgpCols := transpose(sgp, 7)
+	for m := uint8(0); m < 16; m++ {
+		c := mulVecMat(m, sgpCols, 4)
+		for pos := 0; pos < 7; pos++ {
+			if got := decode(c ^ 1<<pos); got != m {
+				t.Errorf("decode(%07b with bit %d flipped) = %04b, want %04b", c, pos, got, m)
+			}
+		}
+		if got := decode(encode(m, sgp)); got != m {
+			t.Errorf("decode(encode(%04b)) = %04b", m, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Command md5extend demonstrates the MD5 length-extension attack. Given
+// the digest of secret || message and the length of secret || message,
+// it prints the message with the glue padding and the suffix appended,
+// and the digest of secret || forged message, without knowing secret:
+//
+//	md5extend -digest 4ce1519874bb2cf40a761703cb03bbeb -len 28 \
+//		-message 'user=alice' -suffix '&admin=true'
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+
+	"six_nine/md5/md5"
+)
+
+func main() {
+	digestHex := flag.String("digest", "", "known MD5 digest of secret || message, in hex")
+	length := flag.Uint64("len", 0, "length of secret || message in bytes")
+	message := flag.String("message", "", "known message, printed in front of the forged data")
+	suffix := flag.String("suffix", "", "data to append")
+	suffixHex := flag.String("suffix-hex", "", "data to append, in hex")
+	flag.Parse()
+
+	digest, err := hex.DecodeString(*digestHex)
+	if err != nil || len(digest) != md5.Size {
+		log.Fatal("-digest must be 16 bytes in hex")
+	}
+	if *length < uint64(len(*message)) {
+		log.Fatal("-len must include the message")
+	}
+
+	data := []byte(*suffix)
+	if *suffixHex != "" {
+		if data, err = hex.DecodeString(*suffixHex); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var sum [md5.Size]byte
+	copy(sum[:], digest)
+	forged, newSum := md5.Extend(sum, *length, data)
+
+	fmt.Printf("message: %x\n", append([]byte(*message), forged...))
+	fmt.Printf("digest:  %x\n", newSum)
+}
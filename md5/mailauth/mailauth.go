@@ -0,0 +1,91 @@
+// Package mailauth implements the MD5-based mail authentication
+// mechanisms CRAM-MD5 (RFC 2195) and POP3 APOP (RFC 1939, section 7) on
+// top of package six_nine/md5/md5.
+package mailauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"six_nine/md5/md5"
+)
+
+// ErrAuth is returned when a response does not match the secret.
+var ErrAuth = errors.New("mailauth: authentication failed")
+
+// NewChallenge returns a challenge of the form <random.time@hostname> for
+// CRAM-MD5 or the APOP greeting. Every challenge must be used only once.
+func NewChallenge(hostname string) (string, error) {
+	if hostname == "" {
+		var err error
+		if hostname, err = os.Hostname(); err != nil {
+			return "", err
+		}
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<%d.%d@%s>", n, time.Now().Unix(), hostname), nil
+}
+
+// CRAMMD5Response returns the client response to a CRAM-MD5 challenge:
+// the user name, a space and the hex HMAC-MD5 of the challenge keyed with
+// the shared secret. It is sent base64-encoded.
+func CRAMMD5Response(username, secret string, challenge []byte) []byte {
+	mac := md5.NewHMAC([]byte(secret))
+	mac.Write(challenge)
+	return []byte(username + " " + hex.EncodeToString(mac.Sum(nil)))
+}
+
+// VerifyCRAMMD5 checks a decoded CRAM-MD5 response to challenge. lookup
+// returns the shared secret of a user and false if there is no such user.
+// VerifyCRAMMD5 returns the authenticated user name or ErrAuth.
+func VerifyCRAMMD5(response, challenge []byte, lookup func(username string) (secret string, ok bool)) (string, error) {
+	i := bytes.LastIndexByte(response, ' ')
+	if i < 0 {
+		return "", ErrAuth
+	}
+	username := string(response[:i])
+	digest, err := hex.DecodeString(string(response[i+1:]))
+	if err != nil {
+		return "", ErrAuth
+	}
+
+	secret, ok := lookup(username)
+	if !ok {
+		return "", ErrAuth
+	}
+
+	mac := md5.NewHMAC([]byte(secret))
+	mac.Write(challenge)
+	if !hmac.Equal(mac.Sum(nil), digest) {
+		return "", ErrAuth
+	}
+
+	return username, nil
+}
+
+// APOPDigest returns the digest for the APOP command: the hex MD5 of the
+// timestamp from the server greeting, brackets included, followed by the
+// shared secret.
+func APOPDigest(timestamp, secret string) string {
+	sum := md5.Sum([]byte(timestamp + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAPOP reports whether digest is the APOP digest of timestamp and
+// secret.
+func VerifyAPOP(timestamp, secret, digest string) bool {
+	want := APOPDigest(timestamp, secret)
+	return hmac.Equal([]byte(want), []byte(digest))
+}
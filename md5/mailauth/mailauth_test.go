@@ -0,0 +1,175 @@
+package mailauth
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+)
+
+var secrets = map[string]string{"tim": "tanstaaftanstaaf", "mrose": "tanstaaf"}
+
+func lookup(username string) (string, bool) {
+	secret, ok := secrets[username]
+	return secret, ok
+}
+
+// Example from RFC 2195, section 2.
+func TestCRAMMD5(t *testing.T) {
+	challenge := []byte("<1896.697170952@postoffice.reston.mci.net>")
+	want := "tim b913a602c7eda7a495b4e6e7334d3890"
+
+	response := CRAMMD5Response("tim", "tanstaaftanstaaf", challenge)
+	if string(response) != want {
+		t.Fatalf("CRAMMD5Response = %q, want %q", response, want)
+	}
+
+	if user, err := VerifyCRAMMD5(response, challenge, lookup); err != nil || user != "tim" {
+		t.Errorf("VerifyCRAMMD5 = %q, %v", user, err)
+	}
+	for _, bad := range []string{
+		"tim b913a602c7eda7a495b4e6e7334d3891",
+		"mrose b913a602c7eda7a495b4e6e7334d3890",
+		"nobody b913a602c7eda7a495b4e6e7334d3890",
+		"tim",
+		"tim zz",
+	} {
+		if _, err := VerifyCRAMMD5([]byte(bad), challenge, lookup); err != ErrAuth {
+			t.Errorf("VerifyCRAMMD5(%q) = %v, want ErrAuth", bad, err)
+		}
+	}
+}
+
+// Example from RFC 1939, section 7.
+func TestAPOP(t *testing.T) {
+	timestamp := "<1896.697170952@dbc.mtview.ca.us>"
+	want := "c4c9334bac560ecc979e58001b3e22fb"
+
+	if got := APOPDigest(timestamp, "tanstaaf"); got != want {
+		t.Fatalf("APOPDigest = %s, want %s", got, want)
+	}
+	if !VerifyAPOP(timestamp, "tanstaaf", want) {
+		t.Error("VerifyAPOP rejected the right digest")
+	}
+	if VerifyAPOP(timestamp, "tanstaaF", want) {
+		t.Error("VerifyAPOP accepted a wrong secret")
+	}
+}
+
+// serve answers one connection: a POP3 greeting with an APOP timestamp,
+// then either an APOP command or AUTH CRAM-MD5.
+func serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	timestamp, err := NewChallenge("localhost")
+	if err != nil {
+		return
+	}
+	conn.Write([]byte("+OK POP3 server ready " + timestamp + "\r\n"))
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(line)
+
+	switch {
+	case len(fields) == 3 && fields[0] == "APOP":
+		if secret, ok := lookup(fields[1]); ok && VerifyAPOP(timestamp, secret, fields[2]) {
+			conn.Write([]byte("+OK " + fields[1] + "\r\n"))
+			return
+		}
+	case len(fields) == 2 && fields[0] == "AUTH" && fields[1] == "CRAM-MD5":
+		challenge, err := NewChallenge("localhost")
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("+ " + base64.StdEncoding.EncodeToString([]byte(challenge)) + "\r\n"))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		response, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+		if err == nil {
+			if user, err := VerifyCRAMMD5(response, []byte(challenge), lookup); err == nil {
+				conn.Write([]byte("+OK " + user + "\r\n"))
+				return
+			}
+		}
+	}
+	conn.Write([]byte("-ERR authentication failed\r\n"))
+}
+
+// login authenticates over a new connection to addr with APOP or, when
+// cram is set, CRAM-MD5, and returns the final server reply.
+func login(t *testing.T, addr, username, secret string, cram bool) string {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	greeting, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cram {
+		conn.Write([]byte("AUTH CRAM-MD5\r\n"))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		challenge, err := base64.StdEncoding.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, "+ ")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response := CRAMMD5Response(username, secret, challenge)
+		conn.Write([]byte(base64.StdEncoding.EncodeToString(response) + "\r\n"))
+	} else {
+		timestamp := greeting[strings.IndexByte(greeting, '<') : strings.LastIndexByte(greeting, '>')+1]
+		conn.Write([]byte("APOP " + username + " " + APOPDigest(timestamp, secret) + "\r\n"))
+	}
+
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(reply)
+}
+
+func TestLoopback(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("cannot listen on loopback:", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	tests := []struct {
+		username, secret string
+		cram             bool
+		want             string
+	}{
+		{"tim", "tanstaaftanstaaf", true, "+OK tim"},
+		{"tim", "wrong", true, "-ERR authentication failed"},
+		{"mrose", "tanstaaf", false, "+OK mrose"},
+		{"mrose", "wrong", false, "-ERR authentication failed"},
+	}
+	for _, test := range tests {
+		if got := login(t, ln.Addr().String(), test.username, test.secret, test.cram); got != test.want {
+			t.Errorf("login(%s, %s, cram=%v) = %q, want %q", test.username, test.secret, test.cram, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package md5
+
+import "encoding/binary"
+
+// Padding returns the bytes MD5 appends to a message of length bytes
+// before the last block is compressed: 0x80, zeros up to 56 bytes modulo
+// 64 and the length in bits, little-endian.
+func Padding(length uint64) []byte {
+	n := 56 - int(length%BlockSize)
+	if n <= 0 {
+		n += BlockSize
+	}
+	pad := make([]byte, n+8)
+	pad[0] = 0x80
+	binary.LittleEndian.PutUint64(pad[n:], length*8)
+	return pad
+}
+
+// Extend performs the length-extension attack on MD5. Given the checksum
+// sum of an unknown message of length bytes, it returns the bytes to
+// append to that message, the glue padding followed by suffix, and the
+// checksum of the extended message, all without knowing the message.
+// This is why MD5(secret || message) must not be used as a MAC; use
+// NewHMAC instead.
+func Extend(sum [Size]byte, length uint64, suffix []byte) (forged []byte, newSum [Size]byte) {
+	glue := Padding(length)
+
+	var d digest
+	for i := range d.s {
+		d.s[i] = binary.LittleEndian.Uint32(sum[4*i:])
+	}
+	d.len = length + uint64(len(glue))
+	d.Write(suffix)
+
+	forged = append(glue, suffix...)
+	return forged, d.checkSum()
+}
@@ -0,0 +1,34 @@
+package md5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExtend(t *testing.T) {
+	suffix := []byte("&admin=true")
+	for _, secretLen := range []int{0, 1, 16, 45, 46, 53, 54, 64, 100} {
+		secret := bytes.Repeat([]byte{'s'}, secretLen)
+		message := []byte("user=alice&role=guest")
+		mac := Sum(append(secret, message...))
+
+		forged, newMAC := Extend(mac, uint64(secretLen+len(message)), suffix)
+		if !bytes.HasSuffix(forged, suffix) {
+			t.Fatalf("secret of %d bytes: forged data %x does not end with the suffix", secretLen, forged)
+		}
+
+		// The party that knows the secret accepts the forged message.
+		forgedMessage := append(append([]byte{}, message...), forged...)
+		if got := Sum(append(secret, forgedMessage...)); got != newMAC {
+			t.Errorf("secret of %d bytes: MD5(secret || %q) = %x, forged %x", secretLen, forgedMessage, got, newMAC)
+		}
+	}
+}
+
+func TestPadding(t *testing.T) {
+	for n := 0; n < 3*BlockSize; n++ {
+		if got := (n + len(Padding(uint64(n)))) % BlockSize; got != 0 {
+			t.Fatalf("%d bytes padded to %d bytes modulo the block size", n, got)
+		}
+	}
+}
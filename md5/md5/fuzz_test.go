@@ -0,0 +1,33 @@
+package md5
+
+import (
+	"bytes"
+	cryptomd5 "crypto/md5"
+	"testing"
+)
+
+// FuzzSum compares Sum and a hash written in two parts with crypto/md5.
+func FuzzSum(f *testing.F) {
+	// Lengths around the padding boundaries.
+	for _, n := range []int{0, 1, 55, 56, 57, 63, 64, 65, 119, 120, 127, 128, 129} {
+		f.Add(bytes.Repeat([]byte{'a'}, n), n/2)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, split int) {
+		want := cryptomd5.Sum(data)
+
+		if got := Sum(data); got != want {
+			t.Fatalf("Sum(%x) = %x, want %x", data, got, want)
+		}
+
+		if split < 0 || split > len(data) {
+			split = len(data) / 2
+		}
+		h := New()
+		h.Write(data[:split])
+		h.Write(data[split:])
+		if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Fatalf("New() with a write split at %d: Sum(%x) = %x, want %x", split, data, got, want)
+		}
+	})
+}
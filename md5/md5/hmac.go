@@ -0,0 +1,11 @@
+package md5
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// NewHMAC returns HMAC-MD5 from RFC 2104 keyed with key.
+func NewHMAC(key []byte) hash.Hash {
+	return hmac.New(New, key)
+}
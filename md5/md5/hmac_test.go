@@ -0,0 +1,41 @@
+package md5
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Test cases from RFC 2202, section 2.
+func TestHMAC(t *testing.T) {
+	tests := []struct {
+		key  []byte
+		data []byte
+		want string
+	}{
+		{bytes.Repeat([]byte{0x0b}, 16), []byte("Hi There"),
+			"9294727a3638bb1c13f48ef8158bfc9d"},
+		{[]byte("Jefe"), []byte("what do ya want for nothing?"),
+			"750c783e6ab0b503eaa86e310a5db738"},
+		{bytes.Repeat([]byte{0xaa}, 16), bytes.Repeat([]byte{0xdd}, 50),
+			"56be34521d144c88dbb8c733f0e8b3f6"},
+		{[]byte{
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d,
+			0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19,
+		}, bytes.Repeat([]byte{0xcd}, 50),
+			"697eaf0aca3a3aea3a75164746ffaa79"},
+		{bytes.Repeat([]byte{0x0c}, 16), []byte("Test With Truncation"),
+			"56461ef2342edc00f9bab995690efd4c"},
+		{bytes.Repeat([]byte{0xaa}, 80), []byte("Test Using Larger Than Block-Size Key - Hash Key First"),
+			"6b1ab7fe4bd7bf8f0b62e6ce61b9d0cd"},
+		{bytes.Repeat([]byte{0xaa}, 80), []byte("Test Using Larger Than Block-Size Key and Larger Than One Block-Size Data"),
+			"6f630fad67cda0ee1fb1f562db3aa53e"},
+	}
+	for i, test := range tests {
+		mac := NewHMAC(test.key)
+		mac.Write(test.data)
+		if got := hex.EncodeToString(mac.Sum(nil)); got != test.want {
+			t.Errorf("test case %d: HMAC-MD5 = %s, want %s", i+1, got, test.want)
+		}
+	}
+}
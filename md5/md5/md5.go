@@ -0,0 +1,189 @@
+// Package md5 implements the MD5 hash algorithm as defined in RFC 1321.
+package md5
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math"
+	"math/bits"
+)
+
+const (
+	// Size is the size of an MD5 checksum in bytes.
+	Size = 16
+
+	// BlockSize is the block size of MD5 in bytes.
+	BlockSize = 64
+)
+
+func f(b uint32, c uint32, d uint32) uint32 {
+	return (b & c) | (^b & d)
+}
+
+func g(b uint32, c uint32, d uint32) uint32 {
+	return (b & d) | (c & ^d)
+}
+
+func h(b uint32, c uint32, d uint32) uint32 {
+	return b ^ c ^ d
+}
+
+func i(b uint32, c uint32, d uint32) uint32 {
+	return c ^ (b | ^d)
+}
+
+// k holds the additive constants of the 64 steps, the integer part of
+// 2^32 * abs(sin(i)) for i from 1 to 64.
+var k [64]uint32
+
+func init() {
+	for j := range k {
+		k[j] = uint32(math.Abs(math.Sin(float64(j+1))) * math.Pow(2, 32))
+	}
+}
+
+// The shifts of the four rounds and, for every round, the message word
+// used in the first step and the increment to the next one.
+var (
+	shifts = [4][4]int{{7, 12, 17, 22}, {5, 9, 14, 20}, {4, 11, 16, 23}, {6, 10, 15, 21}}
+	mStart = [4]int{0, 1, 5, 0}
+	mDelta = [4]int{1, 5, 3, 7}
+	rounds = [4]func(uint32, uint32, uint32) uint32{f, g, h, i}
+)
+
+func block(s *[4]uint32, p []byte) {
+	var m [16]uint32
+	for j := range m {
+		m[j] = binary.LittleEndian.Uint32(p[4*j:])
+	}
+
+	a, b, c, d := s[0], s[1], s[2], s[3]
+	for r, fn := range rounds {
+		mInd := mStart[r]
+		for j := 0; j < 16; j++ {
+			a = b + bits.RotateLeft32(a+fn(b, c, d)+m[mInd]+k[16*r+j], shifts[r][j%4])
+			a, b, c, d = d, a, b, c
+			mInd = (mInd + mDelta[r]) % 16
+		}
+	}
+
+	s[0] += a
+	s[1] += b
+	s[2] += c
+	s[3] += d
+}
+
+// digest keeps the intermediate state of an MD5 computation: A, B, C and
+// D, the input that does not fill a block yet and the message length.
+type digest struct {
+	s   [4]uint32
+	buf [BlockSize]byte
+	len uint64
+}
+
+// New returns a new hash.Hash computing the MD5 checksum. The hash also
+// implements encoding.BinaryMarshaler and encoding.BinaryUnmarshaler to
+// save and restore its internal state.
+func New() hash.Hash {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+func (d *digest) Reset() {
+	d.s = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+	d.len = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	nbuf := int(d.len % BlockSize)
+	d.len += uint64(n)
+	if nbuf > 0 {
+		k := copy(d.buf[nbuf:], p)
+		p = p[k:]
+		if nbuf+k < BlockSize {
+			return n, nil
+		}
+		block(&d.s, d.buf[:])
+	}
+	for len(p) >= BlockSize {
+		block(&d.s, p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	copy(d.buf[:], p)
+	return n, nil
+}
+
+// Sum appends the digest of the data written so far to b without
+// changing the state.
+func (d *digest) Sum(b []byte) []byte {
+	d0 := *d
+	sum := d0.checkSum()
+	return append(b, sum[:]...)
+}
+
+func (d *digest) checkSum() [Size]byte {
+	d.Write(Padding(d.len))
+
+	var sum [Size]byte
+	for i, x := range d.s {
+		binary.LittleEndian.PutUint32(sum[4*i:], x)
+	}
+	return sum
+}
+
+// Sum returns the MD5 checksum of the data.
+func Sum(data []byte) [Size]byte {
+	var d digest
+	d.Reset()
+	d.Write(data)
+	return d.checkSum()
+}
+
+// The saved state is the algorithm tag "md5" and the format version,
+// then A, B, C and D, the buffered input and the message length, all
+// little-endian.
+const (
+	magic         = "md5\x01"
+	marshaledSize = len(magic) + 4*4 + BlockSize + 8
+)
+
+func (d *digest) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, marshaledSize)
+	b = append(b, magic...)
+	for _, x := range d.s {
+		b = binary.LittleEndian.AppendUint32(b, x)
+	}
+	b = append(b, d.buf[:d.len%BlockSize]...)
+	b = append(b, make([]byte, BlockSize-d.len%BlockSize)...)
+	b = binary.LittleEndian.AppendUint64(b, d.len)
+	return b, nil
+}
+
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+		return errors.New("md5: invalid hash state identifier")
+	}
+	if len(b) != marshaledSize {
+		return errors.New("md5: invalid hash state size")
+	}
+
+	b = b[len(magic):]
+	for i := range d.s {
+		d.s[i] = binary.LittleEndian.Uint32(b)
+		b = b[4:]
+	}
+	b = b[copy(d.buf[:], b):]
+	d.len = binary.LittleEndian.Uint64(b)
+	return nil
+}
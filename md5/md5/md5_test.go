@@ -0,0 +1,103 @@
+package md5
+
+import (
+	"bytes"
+	cryptomd5 "crypto/md5"
+	"encoding"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Test suite from RFC 1321, appendix A.5.
+var golden = []struct {
+	in   string
+	want string
+}{
+	{"", "d41d8cd98f00b204e9800998ecf8427e"},
+	{"a", "0cc175b9c0f1b6a831c399e269772661"},
+	{"abc", "900150983cd24fb0d6963f7d28e17f72"},
+	{"message digest", "f96b697d7cb7938d525a2f31aaf161d0"},
+	{"abcdefghijklmnopqrstuvwxyz", "c3fcd3d76192e4007dfb496cca67e13b"},
+	{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "d174ab98d277d9f5a5611c2c9f419d9f"},
+	{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "57edf4a22be3c955ac49da2e2107b67a"},
+}
+
+func TestSum(t *testing.T) {
+	for _, test := range golden {
+		sum := Sum([]byte(test.in))
+		if got := hex.EncodeToString(sum[:]); got != test.want {
+			t.Errorf("Sum(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, test := range golden {
+		h := New()
+		for i := 0; i < len(test.in); i += 3 {
+			end := i + 3
+			if end > len(test.in) {
+				end = len(test.in)
+			}
+			h.Write([]byte(test.in[i:end]))
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.want {
+			t.Errorf("New().Sum(%q) = %s, want %s", test.in, got, test.want)
+		}
+
+		// Sum must not disturb the running state.
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.want {
+			t.Errorf("second Sum(%q) = %s, want %s", test.in, got, test.want)
+		}
+
+		h.Reset()
+		h.Write([]byte(test.in))
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.want {
+			t.Errorf("Sum(%q) after Reset = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	msg := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 5))
+	want := cryptomd5.Sum(msg)
+
+	for split := 0; split <= len(msg); split += 13 {
+		h := New()
+		h.Write(msg[:split])
+		state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := New()
+		if err := r.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatalf("UnmarshalBinary after %d bytes: %v", split, err)
+		}
+		r.Write(msg[split:])
+		if got := r.Sum(nil); !bytes.Equal(got, want[:]) {
+			t.Errorf("resumed after %d bytes: Sum() = %x, want %x", split, got, want)
+		}
+	}
+
+	d := New().(*digest)
+	state, _ := d.MarshalBinary()
+	if err := d.UnmarshalBinary(state[:len(state)-1]); err == nil {
+		t.Error("UnmarshalBinary accepted a truncated state")
+	}
+	state[0] = 's'
+	if err := d.UnmarshalBinary(state); err == nil {
+		t.Error("UnmarshalBinary accepted the state of another algorithm")
+	}
+}
+
+func BenchmarkHash8K(b *testing.B) {
+	h := New()
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.Write(buf)
+	}
+}
@@ -0,0 +1,167 @@
+// Package md5crypt implements the MD5-based password hashing of FreeBSD,
+// glibc and Apache: md5-crypt with the prefix $1$ and its Apache variant
+// apr1 with the prefix $apr1$. Hashes have the form $1$salt$hash.
+//
+// Both are obsolete and only meant for verifying old /etc/shadow and
+// .htpasswd entries.
+package md5crypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"six_nine/md5/md5"
+)
+
+const (
+	PrefixMD5  = "$1$"
+	PrefixAPR1 = "$apr1$"
+
+	// MaxSaltLen is the salt length used; longer salts are cut.
+	MaxSaltLen = 8
+
+	rounds = 1000
+	itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	ErrFormat   = errors.New("md5crypt: unknown hash format")
+	ErrMismatch = errors.New("md5crypt: password does not match the hash")
+)
+
+// Generate hashes password with md5-crypt. An empty salt is replaced with
+// a random one.
+func Generate(password []byte, salt string) (string, error) {
+	return generate(PrefixMD5, password, salt)
+}
+
+// GenerateAPR1 hashes password with Apache's apr1. An empty salt is
+// replaced with a random one.
+func GenerateAPR1(password []byte, salt string) (string, error) {
+	return generate(PrefixAPR1, password, salt)
+}
+
+// Verify checks password against a hash of either format. It returns nil
+// on a match, ErrMismatch if the password is wrong and ErrFormat if the
+// hash is neither md5-crypt nor apr1.
+func Verify(hashed string, password []byte) error {
+	var prefix string
+	switch {
+	case strings.HasPrefix(hashed, PrefixMD5):
+		prefix = PrefixMD5
+	case strings.HasPrefix(hashed, PrefixAPR1):
+		prefix = PrefixAPR1
+	default:
+		return ErrFormat
+	}
+
+	salt, _, ok := strings.Cut(hashed[len(prefix):], "$")
+	if !ok {
+		return ErrFormat
+	}
+
+	got := Crypt(prefix, password, salt)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(hashed)) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func generate(prefix string, password []byte, salt string) (string, error) {
+	if salt == "" {
+		b := make([]byte, MaxSaltLen)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		for i := range b {
+			b[i] = itoa64[b[i]&0x3f]
+		}
+		salt = string(b)
+	}
+	if strings.Contains(salt, "$") {
+		return "", errors.New("md5crypt: salt must not contain '$'")
+	}
+	return Crypt(prefix, password, salt), nil
+}
+
+// Crypt computes the hash of password with the given prefix, PrefixMD5 or
+// PrefixAPR1, and salt. Only the first MaxSaltLen bytes of salt are used,
+// up to the first '$'.
+func Crypt(prefix string, password []byte, salt string) string {
+	if i := strings.IndexByte(salt, '$'); i >= 0 {
+		salt = salt[:i]
+	}
+	if len(salt) > MaxSaltLen {
+		salt = salt[:MaxSaltLen]
+	}
+
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write([]byte(salt))
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	h := md5.New()
+	h.Write(password)
+	h.Write([]byte(prefix))
+	h.Write([]byte(salt))
+	for n := len(password); n > 0; n -= md5.Size {
+		if n < md5.Size {
+			altSum = altSum[:n]
+		}
+		h.Write(altSum)
+	}
+	// The original implementation meant to add a byte of the password for
+	// every set bit of its length, but adds a zero byte instead.
+	for n := len(password); n != 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	final := h.Sum(nil)
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(password)
+		} else {
+			h.Write(final)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i&1 != 0 {
+			h.Write(final)
+		} else {
+			h.Write(password)
+		}
+		final = h.Sum(final[:0])
+	}
+
+	out := make([]byte, 0, len(prefix)+len(salt)+1+22)
+	out = append(out, prefix...)
+	out = append(out, salt...)
+	out = append(out, '$')
+	for _, g := range [5][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		v := uint(final[g[0]])<<16 | uint(final[g[1]])<<8 | uint(final[g[2]])
+		out = appendBase64(out, v, 4)
+	}
+	return string(appendBase64(out, uint(final[11]), 2))
+}
+
+// appendBase64 appends the n low 6-bit groups of v, least significant
+// first, in the crypt(3) alphabet.
+func appendBase64(b []byte, v uint, n int) []byte {
+	for ; n > 0; n-- {
+		b = append(b, itoa64[v&0x3f])
+		v >>= 6
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+package md5crypt
+
+import (
+	"strings"
+	"testing"
+)
+
+// Known hashes, as produced by openssl passwd -1 and -apr1.
+var golden = []struct {
+	prefix   string
+	salt     string
+	password string
+	want     string
+}{
+	{PrefixMD5, "", "abcdefghijk", "$1$$pL/BYSxMXs.jVuSV1lynn1"},
+	{PrefixMD5, "an overlong salt", "abcdfgh", "$1$an overl$ZYftmJDIw8sG5s4gG6r.70"},
+	{PrefixMD5, "12345678", "Lorem ipsum dolor sit amet", "$1$12345678$Suzx8CrBlkNJwVHHHv5tZ."},
+	{PrefixMD5, "deadbeef", "password", "$1$deadbeef$Q7g0UO4hRC0mgQUQ/qkjZ0"},
+	{PrefixMD5, "", "missing salt", "$1$$Lv61fbMiEGprscPkdE9Iw/"},
+	{PrefixMD5, "holy-moly-batman", "1234567", "$1$holy-mol$WKomB0dWknSxdW/e8WYHG0"},
+	{PrefixMD5, "asdfjkl;", "A really long password. Longer than a password has any right to be. Hey bub, don't mess with this password.",
+		"$1$asdfjkl;$DUqPhKwbK4smV0aEMyDdx/"},
+	{PrefixAPR1, "", "abcdefghijk", "$apr1$$NTjzQjNZnhYRPxN6ryN191"},
+	{PrefixAPR1, "an overlong salt", "abcdefgh", "$apr1$an overl$iroRZrWCEoQojCkf6p8LC0"},
+	{PrefixAPR1, "12345678", "Lorem ipsum dolor sit amet", "$apr1$12345678$/DpfgRGBHG8N0cbkmw0Fk/"},
+	{PrefixAPR1, "deadbeef", "password", "$apr1$deadbeef$NWLhx1Ai4ScyoaAboTFco."},
+	{PrefixAPR1, "", "missing salt", "$apr1$$EcorjwkoQz4mYcksVEk6j0"},
+	{PrefixAPR1, "holy-moly-batman", "1234567", "$apr1$holy-mol$/WX0350ZUEkvQkrrVJsrU."},
+}
+
+func TestCrypt(t *testing.T) {
+	for _, test := range golden {
+		if got := Crypt(test.prefix, []byte(test.password), test.salt); got != test.want {
+			t.Errorf("Crypt(%q, %q, %q) = %s, want %s", test.prefix, test.password, test.salt, got, test.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for _, test := range golden {
+		if err := Verify(test.want, []byte(test.password)); err != nil {
+			t.Errorf("Verify(%s, %q) = %v", test.want, test.password, err)
+		}
+		if err := Verify(test.want, []byte(test.password+"x")); err != ErrMismatch {
+			t.Errorf("Verify(%s) with a wrong password = %v, want ErrMismatch", test.want, err)
+		}
+	}
+
+	for _, hashed := range []string{"", "$2a$10$abc", "$1$nosalt", "plain"} {
+		if err := Verify(hashed, []byte("password")); err != ErrFormat {
+			t.Errorf("Verify(%q) = %v, want ErrFormat", hashed, err)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, test := range []struct {
+		generate func([]byte, string) (string, error)
+		prefix   string
+	}{
+		{Generate, PrefixMD5},
+		{GenerateAPR1, PrefixAPR1},
+	} {
+		hashed, err := test.generate([]byte("secret"), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hashed, test.prefix) || len(hashed) != len(test.prefix)+MaxSaltLen+1+22 {
+			t.Errorf("generated hash %q has the wrong form", hashed)
+		}
+		if err := Verify(hashed, []byte("secret")); err != nil {
+			t.Errorf("Verify(%s) = %v", hashed, err)
+		}
+
+		if _, err := test.generate([]byte("secret"), "a$b"); err == nil {
+			t.Error("a salt with '$' was accepted")
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package belt implements the belt block cipher of STB 34.101.31-2011,
+// the Belarusian state standard for data encryption and integrity.
+package belt
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	BlockSize = 16
+	KeySize   = 32
+)
+
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+	return "belt: invalid key size " + strconv.Itoa(int(k))
+}
+
+// H is the substitution of the standard, table 1.
+var H = [256]uint8{
+	0xB1, 0x94, 0xBA, 0xC8, 0x0A, 0x08, 0xF5, 0x3B, 0x36, 0x6D, 0x00, 0x8E, 0x58, 0x4A, 0x5D, 0xE4,
+	0x85, 0x04, 0xFA, 0x9D, 0x1B, 0xB6, 0xC7, 0xAC, 0x25, 0x2E, 0x72, 0xC2, 0x02, 0xFD, 0xCE, 0x0D,
+	0x5B, 0xE3, 0xD6, 0x12, 0x17, 0xB9, 0x61, 0x81, 0xFE, 0x67, 0x86, 0xAD, 0x71, 0x6B, 0x89, 0x0B,
+	0x5C, 0xB0, 0xC0, 0xFF, 0x33, 0xC3, 0x56, 0xB8, 0x35, 0xC4, 0x05, 0xAE, 0xD8, 0xE0, 0x7F, 0x99,
+	0xE1, 0x2B, 0xDC, 0x1A, 0xE2, 0x82, 0x57, 0xEC, 0x70, 0x3F, 0xCC, 0xF0, 0x95, 0xEE, 0x8D, 0xF1,
+	0xC1, 0xAB, 0x76, 0x38, 0x9F, 0xE6, 0x78, 0xCA, 0xF7, 0xC6, 0xF8, 0x60, 0xD5, 0xBB, 0x9C, 0x4F,
+	0xF3, 0x3C, 0x65, 0x7B, 0x63, 0x7C, 0x30, 0x6A, 0xDD, 0x4E, 0xA7, 0x79, 0x9E, 0xB2, 0x3D, 0x31,
+	0x3E, 0x98, 0xB5, 0x6E, 0x27, 0xD3, 0xBC, 0xCF, 0x59, 0x1E, 0x18, 0x1F, 0x4C, 0x5A, 0xB7, 0x93,
+	0xE9, 0xDE, 0xE7, 0x2C, 0x8F, 0x0C, 0x0F, 0xA6, 0x2D, 0xDB, 0x49, 0xF4, 0x6F, 0x73, 0x96, 0x47,
+	0x06, 0x07, 0x53, 0x16, 0xED, 0x24, 0x7A, 0x37, 0x39, 0xCB, 0xA3, 0x83, 0x03, 0xA9, 0x8B, 0xF6,
+	0x92, 0xBD, 0x9B, 0x1C, 0xE5, 0xD1, 0x41, 0x01, 0x54, 0x45, 0xFB, 0xC9, 0x5E, 0x4D, 0x0E, 0xF2,
+	0x68, 0x20, 0x80, 0xAA, 0x22, 0x7D, 0x64, 0x2F, 0x26, 0x87, 0xF9, 0x34, 0x90, 0x40, 0x55, 0x11,
+	0xBE, 0x32, 0x97, 0x13, 0x43, 0xFC, 0x9A, 0x48, 0xA0, 0x2A, 0x88, 0x5F, 0x19, 0x4B, 0x09, 0xA1,
+	0x7E, 0xCD, 0xA4, 0xD0, 0x15, 0x44, 0xAF, 0x8C, 0xA5, 0x84, 0x50, 0xBF, 0x66, 0xD2, 0xE8, 0x8A,
+	0xA2, 0xD7, 0x46, 0x52, 0x42, 0xA8, 0xDF, 0xB3, 0x69, 0x74, 0xC5, 0x51, 0xEB, 0x23, 0x29, 0x21,
+	0xD4, 0xEF, 0xD9, 0xB4, 0x3A, 0x62, 0x28, 0x75, 0x91, 0x14, 0x10, 0xEA, 0x77, 0x6C, 0xDA, 0x1D,
+}
+
+// G substitutes every byte of u with H and rotates the result left by r
+// bits.
+func G(r int, u uint32) uint32 {
+	x := uint32(H[byte(u)]) | uint32(H[byte(u>>8)])<<8 |
+		uint32(H[byte(u>>16)])<<16 | uint32(H[byte(u>>24)])<<24
+	return bits.RotateLeft32(x, r)
+}
+
+// ExpandKey turns a 128-, 192- or 256-bit key into the 256-bit key that
+// NewCipher takes, as in section 6.1.2 of the standard: a 128-bit key is
+// repeated and a 192-bit key θ1..θ6 is followed by θ1^θ2^θ3 and
+// θ4^θ5^θ6.
+func ExpandKey(key []byte) ([KeySize]byte, error) {
+	var k [KeySize]byte
+	switch len(key) {
+	case 16:
+		copy(k[:], key)
+		copy(k[16:], key)
+	case 24:
+		copy(k[:], key)
+		for i := 0; i < 4; i++ {
+			k[24+i] = key[i] ^ key[4+i] ^ key[8+i]
+			k[28+i] = key[12+i] ^ key[16+i] ^ key[20+i]
+		}
+	case 32:
+		copy(k[:], key)
+	default:
+		return k, KeySizeError(len(key))
+	}
+	return k, nil
+}
+
+type beltCipher struct {
+	k [56]uint32
+}
+
+// NewCipher creates and returns a new cipher.Block with the 256-bit key.
+// Shorter keys must be passed through ExpandKey first.
+func NewCipher(key [KeySize]byte) cipher.Block {
+	return newCipher(key)
+}
+
+func newCipher(key [KeySize]byte) *beltCipher {
+	c := new(beltCipher)
+	for i := range c.k {
+		c.k[i] = binary.LittleEndian.Uint32(key[4*(i%8):])
+	}
+	return c
+}
+
+func (c *beltCipher) BlockSize() int {
+	return BlockSize
+}
+
+func (c *beltCipher) Encrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("belt: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("belt: output not full block")
+	}
+	a := binary.LittleEndian.Uint32(src[0:])
+	b := binary.LittleEndian.Uint32(src[4:])
+	cc := binary.LittleEndian.Uint32(src[8:])
+	d := binary.LittleEndian.Uint32(src[12:])
+
+	a, b, cc, d = c.encrypt(a, b, cc, d)
+
+	binary.LittleEndian.PutUint32(dst[0:], a)
+	binary.LittleEndian.PutUint32(dst[4:], b)
+	binary.LittleEndian.PutUint32(dst[8:], cc)
+	binary.LittleEndian.PutUint32(dst[12:], d)
+}
+
+func (c *beltCipher) Decrypt(dst, src []byte) {
+	if len(src) < BlockSize {
+		panic("belt: input not full block")
+	}
+	if len(dst) < BlockSize {
+		panic("belt: output not full block")
+	}
+	a := binary.LittleEndian.Uint32(src[0:])
+	b := binary.LittleEndian.Uint32(src[4:])
+	cc := binary.LittleEndian.Uint32(src[8:])
+	d := binary.LittleEndian.Uint32(src[12:])
+
+	a, b, cc, d = c.decrypt(a, b, cc, d)
+
+	binary.LittleEndian.PutUint32(dst[0:], a)
+	binary.LittleEndian.PutUint32(dst[4:], b)
+	binary.LittleEndian.PutUint32(dst[8:], cc)
+	binary.LittleEndian.PutUint32(dst[12:], d)
+}
+
+// encrypt is the transformation F of section 6.1.3. The key K_j of the
+// standard is k[j-1].
+func (c *beltCipher) encrypt(a, b, cc, d uint32) (uint32, uint32, uint32, uint32) {
+	for i := 1; i <= 8; i++ {
+		k := c.k[7*i-7 : 7*i]
+		b ^= G(5, a+k[0])
+		cc ^= G(21, d+k[1])
+		a -= G(13, b+k[2])
+		e := G(21, b+cc+k[3]) ^ uint32(i)
+		b += e
+		cc -= e
+		d += G(13, cc+k[4])
+		b ^= G(21, a+k[5])
+		cc ^= G(5, d+k[6])
+		a, b = b, a
+		cc, d = d, cc
+		b, cc = cc, b
+	}
+	return b, d, a, cc
+}
+
+// decrypt is the inverse transformation of section 6.1.4.
+func (c *beltCipher) decrypt(a, b, cc, d uint32) (uint32, uint32, uint32, uint32) {
+	for i := 8; i >= 1; i-- {
+		k := c.k[7*i-7 : 7*i]
+		b ^= G(5, a+k[6])
+		cc ^= G(21, d+k[5])
+		a -= G(13, b+k[4])
+		e := G(21, b+cc+k[3]) ^ uint32(i)
+		b += e
+		cc -= e
+		d += G(13, cc+k[2])
+		b ^= G(21, a+k[1])
+		cc ^= G(5, d+k[0])
+		a, b = b, a
+		cc, d = d, cc
+		a, d = d, a
+	}
+	return cc, a, d, b
+}
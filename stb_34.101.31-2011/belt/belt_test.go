@@ -0,0 +1,87 @@
+package belt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(t testing.TB, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func decodeKey(t testing.TB, s string) [KeySize]byte {
+	var key [KeySize]byte
+	copy(key[:], decodeHex(t, s))
+	return key
+}
+
+// Examples from STB 34.101.31-2011, tables A.1 and A.4.
+func TestCipher(t *testing.T) {
+	tests := []struct {
+		key string
+		pt  string
+		ct  string
+	}{
+		{
+			"e9dee72c8f0c0fa62ddb49f46f73964706075316ed247a3739cba38303a98bf6",
+			"b194bac80a08f53b366d008e584a5de4",
+			"69cca1c93557c9e3d66bc3e0fa88fa6e",
+		},
+		{
+			"92bd9b1ce5d141015445fbc95e4d0ef2682080aa227d642f2687f93490405511",
+			"0dc5300600cab840b38448e5e993f421",
+			"e12bdc1ae28257ec703fccf095ee8df1",
+		},
+	}
+	for _, test := range tests {
+		c := NewCipher(decodeKey(t, test.key))
+		pt, ct := decodeHex(t, test.pt), decodeHex(t, test.ct)
+		buf := make([]byte, BlockSize)
+
+		c.Encrypt(buf, pt)
+		if !bytes.Equal(buf, ct) {
+			t.Errorf("Encrypt(%s) = %x, want %s", test.pt, buf, test.ct)
+		}
+		c.Decrypt(buf, ct)
+		if !bytes.Equal(buf, pt) {
+			t.Errorf("Decrypt(%s) = %x, want %s", test.ct, buf, test.pt)
+		}
+	}
+}
+
+func TestExpandKey(t *testing.T) {
+	key := decodeHex(t, "e9dee72c8f0c0fa62ddb49f46f73964706075316ed247a3739cba38303a98bf6")
+
+	k, err := ExpandKey(key[:16])
+	if err != nil || !bytes.Equal(k[:16], key[:16]) || !bytes.Equal(k[16:], key[:16]) {
+		t.Errorf("ExpandKey(128 bits) = %x, %v", k, err)
+	}
+
+	k, err = ExpandKey(key[:24])
+	want := decodeHex(t, "e9dee72c8f0c0fa62ddb49f46f73964706075316ed247a37"+"4b09a17e"+"8450bf66")
+	if err != nil || !bytes.Equal(k[:], want) {
+		t.Errorf("ExpandKey(192 bits) = %x, %v, want %x", k, err, want)
+	}
+
+	if k, err = ExpandKey(key); err != nil || !bytes.Equal(k[:], key) {
+		t.Errorf("ExpandKey(256 bits) = %x, %v", k, err)
+	}
+
+	if _, err := ExpandKey(key[:20]); err != KeySizeError(20) {
+		t.Errorf("ExpandKey(160 bits) = %v, want KeySizeError(20)", err)
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	c := NewCipher([KeySize]byte{})
+	buf := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(buf, buf)
+	}
+}
@@ -0,0 +1,103 @@
+package belt
+
+import "crypto/cipher"
+
+// Cipher block chaining mode, belt-cbc of section 7.2: every plaintext
+// block is XORed with the previous ciphertext block, the first one with
+// the synchronization message, before it is encrypted. A final partial
+// block is handled with ciphertext stealing.
+
+type cbc struct {
+	b  cipher.Block
+	iv [BlockSize]byte
+}
+
+func newCBC(b cipher.Block, iv []byte) *cbc {
+	if len(iv) != BlockSize {
+		panic("belt: IV length must equal block size")
+	}
+	x := &cbc{b: b}
+	copy(x.iv[:], iv)
+	return x
+}
+
+type cbcEncrypter cbc
+
+// NewCBCEncrypter returns a cipher.BlockMode which encrypts in belt-cbc.
+// The iv is the synchronization message S and must be one block long.
+// CryptBlocks takes any length of at least one block. If the length is
+// not a multiple of BlockSize, the last two blocks are encrypted with
+// ciphertext stealing, which ends the message.
+func NewCBCEncrypter(b cipher.Block, iv []byte) cipher.BlockMode {
+	return (*cbcEncrypter)(newCBC(b, iv))
+}
+
+func (x *cbcEncrypter) BlockSize() int {
+	return BlockSize
+}
+
+func (x *cbcEncrypter) CryptBlocks(dst, src []byte) {
+	checkSizes(dst, src)
+	n, r := splitTail(len(src))
+	for i := 0; i < n; i += BlockSize {
+		xorBytes(x.iv[:], x.iv[:], src[i:i+BlockSize])
+		x.b.Encrypt(x.iv[:], x.iv[:])
+		copy(dst[i:], x.iv[:])
+	}
+	if r == 0 {
+		return
+	}
+
+	// (Y_n || r) = F(X_{n-1} ^ Y_{n-2}),
+	// Y_{n-1} = F((X_n || 0) ^ (Y_n || r)).
+	var t [BlockSize]byte
+	xorBytes(t[:], x.iv[:], src[n:n+BlockSize])
+	x.b.Encrypt(t[:], t[:])
+	for i := 0; i < r; i++ {
+		t[i], dst[n+BlockSize+i] = t[i]^src[n+BlockSize+i], t[i]
+	}
+	x.b.Encrypt(dst[n:n+BlockSize], t[:])
+}
+
+type cbcDecrypter cbc
+
+// NewCBCDecrypter returns a cipher.BlockMode which decrypts in belt-cbc.
+// The lengths it takes are as for NewCBCEncrypter.
+func NewCBCDecrypter(b cipher.Block, iv []byte) cipher.BlockMode {
+	return (*cbcDecrypter)(newCBC(b, iv))
+}
+
+func (x *cbcDecrypter) BlockSize() int {
+	return BlockSize
+}
+
+func (x *cbcDecrypter) CryptBlocks(dst, src []byte) {
+	checkSizes(dst, src)
+	n, r := splitTail(len(src))
+	var next [BlockSize]byte
+	for i := 0; i < n; i += BlockSize {
+		copy(next[:], src[i:i+BlockSize])
+		x.b.Decrypt(dst[i:i+BlockSize], src[i:i+BlockSize])
+		xorBytes(dst[i:i+BlockSize], dst[i:i+BlockSize], x.iv[:])
+		x.iv = next
+	}
+	if r == 0 {
+		return
+	}
+
+	// (X_n || r) = F^-1(Y_{n-1}) ^ (Y_n || 0),
+	// X_{n-1} = F^-1(Y_n || r) ^ Y_{n-2}.
+	var t [BlockSize]byte
+	x.b.Decrypt(t[:], src[n:n+BlockSize])
+	for i := 0; i < r; i++ {
+		t[i], dst[n+BlockSize+i] = src[n+BlockSize+i], t[i]^src[n+BlockSize+i]
+	}
+	x.b.Decrypt(dst[n:n+BlockSize], t[:])
+	xorBytes(dst[n:n+BlockSize], dst[n:n+BlockSize], x.iv[:])
+}
+
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
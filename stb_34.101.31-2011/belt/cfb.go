@@ -0,0 +1,59 @@
+package belt
+
+import "crypto/cipher"
+
+// Cipher feedback mode, belt-cfb of section 7.3: every block of gamma is
+// the encryption of the previous ciphertext block, the first one is the
+// encryption of the synchronization message. The last block of gamma is
+// cut to the length of the data.
+
+type cfb struct {
+	b       cipher.Block
+	next    [BlockSize]byte
+	gamma   [BlockSize]byte
+	used    int
+	decrypt bool
+}
+
+// NewCFBEncrypter returns a cipher.Stream which encrypts in belt-cfb.
+// The iv is the synchronization message S and must be one block long.
+func NewCFBEncrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, false)
+}
+
+// NewCFBDecrypter returns a cipher.Stream which decrypts in belt-cfb.
+func NewCFBDecrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, true)
+}
+
+func newCFB(b cipher.Block, iv []byte, decrypt bool) *cfb {
+	if len(iv) != BlockSize {
+		panic("belt: IV length must equal block size")
+	}
+
+	x := &cfb{b: b, used: BlockSize, decrypt: decrypt}
+	copy(x.next[:], iv)
+
+	return x
+}
+
+func (x *cfb) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("belt: output smaller than input")
+	}
+	for i := range src {
+		if x.used == BlockSize {
+			x.b.Encrypt(x.gamma[:], x.next[:])
+			x.used = 0
+		}
+		if x.decrypt {
+			c := src[i]
+			dst[i] = c ^ x.gamma[x.used]
+			x.next[x.used] = c
+		} else {
+			dst[i] = src[i] ^ x.gamma[x.used]
+			x.next[x.used] = dst[i]
+		}
+		x.used++
+	}
+}
@@ -0,0 +1,61 @@
+package belt
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Counter mode, belt-ctr of section 7.4: the encrypted synchronization
+// message s is incremented modulo 2^128 before every block, and the
+// encryption of s is XORed with the data. The last block of gamma is cut
+// to the length of the data.
+
+type ctr struct {
+	b     cipher.Block
+	lo    uint64
+	hi    uint64
+	gamma [BlockSize]byte
+	used  int
+}
+
+// NewCTR returns a cipher.Stream which encrypts or decrypts in belt-ctr.
+// The iv is the synchronization message S and must be one block long.
+func NewCTR(b cipher.Block, iv []byte) cipher.Stream {
+	if len(iv) != BlockSize {
+		panic("belt: IV length must equal block size")
+	}
+
+	var s [BlockSize]byte
+	b.Encrypt(s[:], iv)
+
+	return &ctr{
+		b:    b,
+		lo:   binary.LittleEndian.Uint64(s[:8]),
+		hi:   binary.LittleEndian.Uint64(s[8:]),
+		used: BlockSize,
+	}
+}
+
+func (x *ctr) refill() {
+	var carry uint64
+	x.lo, carry = bits.Add64(x.lo, 1, 0)
+	x.hi += carry
+	binary.LittleEndian.PutUint64(x.gamma[:8], x.lo)
+	binary.LittleEndian.PutUint64(x.gamma[8:], x.hi)
+	x.b.Encrypt(x.gamma[:], x.gamma[:])
+	x.used = 0
+}
+
+func (x *ctr) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("belt: output smaller than input")
+	}
+	for i := range src {
+		if x.used == BlockSize {
+			x.refill()
+		}
+		dst[i] = src[i] ^ x.gamma[x.used]
+		x.used++
+	}
+}
@@ -0,0 +1,159 @@
+package belt
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// Authenticated encryption, belt-dwp of section 7.6: the data is encrypted
+// in belt-ctr, and the associated data, the ciphertext and their lengths
+// are authenticated with a polynomial MAC in GF(2^128) keyed with
+// r = F(F(S)).
+
+const (
+	dwpNonceSize = BlockSize
+	dwpTagSize   = 8
+)
+
+var errOpen = errors.New("belt: message authentication failed")
+
+type dwp struct {
+	b cipher.Block
+}
+
+// NewDWP returns belt-dwp over the block cipher, which must come from
+// NewCipher. The nonce is the synchronization message S; it must be
+// BlockSize bytes long and never be reused with the same key. The tag is
+// 8 bytes long.
+func NewDWP(b cipher.Block) (cipher.AEAD, error) {
+	if b.BlockSize() != BlockSize {
+		return nil, errors.New("belt: belt-dwp needs a 128-bit block cipher")
+	}
+	return &dwp{b: b}, nil
+}
+
+func (d *dwp) NonceSize() int {
+	return dwpNonceSize
+}
+
+func (d *dwp) Overhead() int {
+	return dwpTagSize
+}
+
+// gfMul multiplies x by y in GF(2^128) defined by x^128 + x^7 + x^2 + x
+// + 1. The bit i of a little-endian 128-bit number is the coefficient of
+// x^i.
+func gfMul(x, y [2]uint64) [2]uint64 {
+	var z [2]uint64
+	for i := 127; i >= 0; i-- {
+		// z = z * x
+		carry := z[1] >> 63
+		z[1] = z[1]<<1 | z[0]>>63
+		z[0] = z[0]<<1 ^ (0x87 & -carry)
+
+		bit := (x[i/64] >> (i % 64)) & 1
+		z[0] ^= y[0] & -bit
+		z[1] ^= y[1] & -bit
+	}
+	return z
+}
+
+// dwpMAC accumulates t = (t ^ block) * r over zero-padded blocks.
+type dwpMAC struct {
+	r [2]uint64
+	t [2]uint64
+}
+
+func (m *dwpMAC) update(p []byte) {
+	for len(p) > 0 {
+		var block [BlockSize]byte
+		n := copy(block[:], p)
+		p = p[n:]
+		m.t[0] ^= binary.LittleEndian.Uint64(block[:8])
+		m.t[1] ^= binary.LittleEndian.Uint64(block[8:])
+		m.t = gfMul(m.t, m.r)
+	}
+}
+
+// setup returns the counter mode stream and the MAC with t set to the
+// first half of H and the associated data processed.
+func (d *dwp) setup(nonce, additionalData []byte) (cipher.Stream, *dwpMAC) {
+	if len(nonce) != dwpNonceSize {
+		panic("belt: incorrect nonce length given to belt-dwp")
+	}
+
+	var r [BlockSize]byte
+	d.b.Encrypt(r[:], nonce)
+	d.b.Encrypt(r[:], r[:])
+
+	m := &dwpMAC{
+		r: [2]uint64{binary.LittleEndian.Uint64(r[:8]), binary.LittleEndian.Uint64(r[8:])},
+		t: [2]uint64{binary.LittleEndian.Uint64(H[:8]), binary.LittleEndian.Uint64(H[8:16])},
+	}
+	m.update(additionalData)
+
+	return NewCTR(d.b, nonce), m
+}
+
+// tag finishes the MAC with the lengths in bits of the associated data
+// and the ciphertext and returns the first 64 bits of F(t).
+func (d *dwp) tag(m *dwpMAC, adLen, ctLen int) [dwpTagSize]byte {
+	m.t[0] ^= uint64(adLen) * 8
+	m.t[1] ^= uint64(ctLen) * 8
+	m.t = gfMul(m.t, m.r)
+
+	var t [BlockSize]byte
+	binary.LittleEndian.PutUint64(t[:8], m.t[0])
+	binary.LittleEndian.PutUint64(t[8:], m.t[1])
+	d.b.Encrypt(t[:], t[:])
+
+	var out [dwpTagSize]byte
+	copy(out[:], t[:])
+	return out
+}
+
+func (d *dwp) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	stream, m := d.setup(nonce, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+dwpTagSize)
+	ct := out[:len(plaintext)]
+	stream.XORKeyStream(ct, plaintext)
+	m.update(ct)
+
+	t := d.tag(m, len(additionalData), len(plaintext))
+	copy(out[len(plaintext):], t[:])
+	return ret
+}
+
+func (d *dwp) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < dwpTagSize {
+		return nil, errOpen
+	}
+	ct, tag := ciphertext[:len(ciphertext)-dwpTagSize], ciphertext[len(ciphertext)-dwpTagSize:]
+
+	stream, m := d.setup(nonce, additionalData)
+	m.update(ct)
+	want := d.tag(m, len(additionalData), len(ct))
+	if subtle.ConstantTimeCompare(want[:], tag) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ct))
+	stream.XORKeyStream(out, ct)
+	return ret, nil
+}
+
+// sliceForAppend extends in by n bytes and returns the whole slice and
+// the new part.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
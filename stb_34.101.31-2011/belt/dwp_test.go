@@ -0,0 +1,96 @@
+package belt
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Examples from STB 34.101.31-2011, appendix A.
+func TestDWP(t *testing.T) {
+	tests := []struct {
+		key, nonce, ad, pt, ct, tag string
+	}{
+		{
+			testKey, testIV,
+			"8504fa9d1bb6c7ac252e72c202fdce0d5be3d61217b96181fe6786ad716b890b",
+			"b194bac80a08f53b366d008e584a5de4",
+			"52c9af96ff50f64435fc43def56bd797",
+			"3b2e0aeb2b91854b",
+		},
+		{
+			"92bd9b1ce5d141015445fbc95e4d0ef2682080aa227d642f2687f93490405511",
+			"7ecda4d01544af8ca58450bf66d2e88a",
+			"c1ab76389fe678caf7c6f860d5bb9c4ff33c657b637c306add4ea7799eb23d31",
+			"df181ed008a20f43dcbbb93650dad34b",
+			"e12bdc1ae28257ec703fccf095ee8df1",
+			"6a2c2c94c4150dc0",
+		},
+	}
+	for _, test := range tests {
+		aead, err := NewDWP(NewCipher(decodeKey(t, test.key)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce, ad, pt := decodeHex(t, test.nonce), decodeHex(t, test.ad), decodeHex(t, test.pt)
+		want := decodeHex(t, test.ct+test.tag)
+
+		sealed := aead.Seal(nil, nonce, pt, ad)
+		if !bytes.Equal(sealed, want) {
+			t.Errorf("Seal(%s) = %x, want %x", test.pt, sealed, want)
+		}
+
+		opened, err := aead.Open(nil, nonce, want, ad)
+		if err != nil || !bytes.Equal(opened, pt) {
+			t.Errorf("Open(%x) = %x, %v, want %s", want, opened, err, test.pt)
+		}
+	}
+}
+
+func TestDWPTampering(t *testing.T) {
+	aead, _ := NewDWP(NewCipher(decodeKey(t, testKey)))
+	nonce := decodeHex(t, testIV)
+	ad := []byte("header: not secret")
+	pt := []byte("a message longer than one block, with a partial last block")
+
+	sealed := aead.Seal([]byte("prefix"), nonce, pt, ad)
+	if !bytes.HasPrefix(sealed, []byte("prefix")) || len(sealed) != len("prefix")+len(pt)+aead.Overhead() {
+		t.Fatalf("Seal did not append to dst: %x", sealed)
+	}
+	sealed = sealed[len("prefix"):]
+
+	opened, err := aead.Open(sealed[:0], nonce, sealed, ad)
+	if err != nil || !bytes.Equal(opened, pt) {
+		t.Fatalf("Open in place = %q, %v", opened, err)
+	}
+	sealed = aead.Seal(nil, nonce, pt, ad)
+
+	for i := 0; i < len(sealed); i++ {
+		bad := append([]byte{}, sealed...)
+		bad[i] ^= 0x01
+		if _, err := aead.Open(nil, nonce, bad, ad); err == nil {
+			t.Errorf("Open accepted a ciphertext with byte %d changed", i)
+		}
+	}
+	for i := range ad {
+		bad := append([]byte{}, ad...)
+		bad[i] ^= 0x80
+		if _, err := aead.Open(nil, nonce, sealed, bad); err == nil {
+			t.Errorf("Open accepted associated data with byte %d changed", i)
+		}
+	}
+	if _, err := aead.Open(nil, nonce, sealed, nil); err == nil {
+		t.Error("Open accepted missing associated data")
+	}
+	if _, err := aead.Open(nil, nonce, sealed[:len(sealed)-1], ad); err == nil {
+		t.Error("Open accepted a truncated tag")
+	}
+	if _, err := aead.Open(nil, nonce, sealed[:4], ad); err == nil {
+		t.Error("Open accepted a message shorter than the tag")
+	}
+
+	otherNonce := append([]byte{}, nonce...)
+	otherNonce[0] ^= 1
+	if _, err := aead.Open(nil, otherNonce, sealed, ad); err == nil {
+		t.Error("Open accepted a different nonce")
+	}
+}
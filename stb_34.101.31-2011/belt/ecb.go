@@ -0,0 +1,100 @@
+package belt
+
+import "crypto/cipher"
+
+// Electronic codebook mode, belt-ecb of section 7.1: every block is
+// encrypted on its own. A final partial block takes the tail of the
+// encryption of the block before it (ciphertext stealing), so the
+// ciphertext is as long as the plaintext.
+
+type ecb struct {
+	b cipher.Block
+}
+
+type ecbEncrypter ecb
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts in belt-ecb.
+// CryptBlocks takes any length of at least one block. If the length is
+// not a multiple of BlockSize, the last two blocks are encrypted with
+// ciphertext stealing, which ends the message.
+func NewECBEncrypter(b cipher.Block) cipher.BlockMode {
+	return &ecbEncrypter{b: b}
+}
+
+func (x *ecbEncrypter) BlockSize() int {
+	return BlockSize
+}
+
+func (x *ecbEncrypter) CryptBlocks(dst, src []byte) {
+	checkSizes(dst, src)
+	n, r := splitTail(len(src))
+	for i := 0; i < n; i += BlockSize {
+		x.b.Encrypt(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	if r == 0 {
+		return
+	}
+
+	// (Y_n || r) = F(X_{n-1}), Y_{n-1} = F(X_n || r).
+	var t [BlockSize]byte
+	x.b.Encrypt(t[:], src[n:n+BlockSize])
+	swapTail(t[:r], dst[n+BlockSize:], src[n+BlockSize:])
+	x.b.Encrypt(dst[n:n+BlockSize], t[:])
+}
+
+type ecbDecrypter ecb
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts in belt-ecb.
+// The lengths it takes are as for NewECBEncrypter.
+func NewECBDecrypter(b cipher.Block) cipher.BlockMode {
+	return &ecbDecrypter{b: b}
+}
+
+func (x *ecbDecrypter) BlockSize() int {
+	return BlockSize
+}
+
+func (x *ecbDecrypter) CryptBlocks(dst, src []byte) {
+	checkSizes(dst, src)
+	n, r := splitTail(len(src))
+	for i := 0; i < n; i += BlockSize {
+		x.b.Decrypt(dst[i:i+BlockSize], src[i:i+BlockSize])
+	}
+	if r == 0 {
+		return
+	}
+
+	// (X_n || r) = F^-1(Y_{n-1}), X_{n-1} = F^-1(Y_n || r).
+	var t [BlockSize]byte
+	x.b.Decrypt(t[:], src[n:n+BlockSize])
+	swapTail(t[:r], dst[n+BlockSize:], src[n+BlockSize:])
+	x.b.Decrypt(dst[n:n+BlockSize], t[:])
+}
+
+func checkSizes(dst, src []byte) {
+	if len(src) > 0 && len(src) < BlockSize {
+		panic("belt: input shorter than a block")
+	}
+	if len(dst) < len(src) {
+		panic("belt: output smaller than input")
+	}
+}
+
+// splitTail returns the length of the blocks that are processed as usual
+// and the length r of the final partial block. When r is not zero, the
+// two blocks from n on are left for ciphertext stealing.
+func splitTail(size int) (n, r int) {
+	r = size % BlockSize
+	if r == 0 {
+		return size, 0
+	}
+	return size - r - BlockSize, r
+}
+
+// swapTail moves t to dst and src to t, byte by byte, so that dst and
+// src may be the same.
+func swapTail(t, dst, src []byte) {
+	for i := range t {
+		t[i], dst[i] = src[i], t[i]
+	}
+}
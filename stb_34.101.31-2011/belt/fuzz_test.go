@@ -0,0 +1,64 @@
+package belt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+// FuzzRoundTrip encrypts and decrypts data in every mode, including
+// belt-dwp with the key data as associated data. The key and the
+// synchronization message are cut or zero-padded to size.
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte("0123456789abcdef0123456789abcdef"), []byte("synchronization!"), []byte("attack at dawn, not before sunrise"))
+	f.Add([]byte{}, []byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, keyData, ivData, data []byte) {
+		var key [KeySize]byte
+		copy(key[:], keyData)
+		iv := make([]byte, BlockSize)
+		copy(iv, ivData)
+		c := NewCipher(key)
+
+		if len(data) >= BlockSize {
+			for _, mode := range []struct {
+				name     string
+				enc, dec cipher.BlockMode
+			}{
+				{"belt-ecb", NewECBEncrypter(c), NewECBDecrypter(c)},
+				{"belt-cbc", NewCBCEncrypter(c, iv), NewCBCDecrypter(c, iv)},
+			} {
+				buf := make([]byte, len(data))
+				mode.enc.CryptBlocks(buf, data)
+				mode.dec.CryptBlocks(buf, buf)
+				if !bytes.Equal(buf, data) {
+					t.Fatalf("%s: decrypted %x, want %x", mode.name, buf, data)
+				}
+			}
+		}
+
+		for _, mode := range []struct {
+			name     string
+			enc, dec cipher.Stream
+		}{
+			{"belt-cfb", NewCFBEncrypter(c, iv), NewCFBDecrypter(c, iv)},
+			{"belt-ctr", NewCTR(c, iv), NewCTR(c, iv)},
+		} {
+			buf := make([]byte, len(data))
+			mode.enc.XORKeyStream(buf, data)
+			mode.dec.XORKeyStream(buf, buf)
+			if !bytes.Equal(buf, data) {
+				t.Fatalf("%s: decrypted %x, want %x", mode.name, buf, data)
+			}
+		}
+
+		aead, err := NewDWP(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opened, err := aead.Open(nil, iv, aead.Seal(nil, iv, data, keyData), keyData)
+		if err != nil || !bytes.Equal(opened, data) {
+			t.Fatalf("belt-dwp: opened %x, %v, want %x", opened, err, data)
+		}
+	})
+}
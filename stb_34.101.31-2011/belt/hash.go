@@ -0,0 +1,131 @@
+package belt
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// HashSize is the size of a belt-hash digest in bytes.
+	HashSize = 32
+
+	// HashBlockSize is the block size of belt-hash in bytes.
+	HashBlockSize = 32
+)
+
+// compress is belt-compress of section 6.3 on X = X1 || X2 || X3 || X4.
+// It returns σ1(X) = S and σ2(X) = Y1 || Y2, where
+//
+//	S  = F[X1 || X2](X3 ^ X4) ^ X3 ^ X4,
+//	Y1 = F[S || X4](X1) ^ X1,
+//	Y2 = F[¬S || X3](X2) ^ X2.
+func compress(x *[4 * BlockSize]byte) (s [BlockSize]byte, y [2 * BlockSize]byte) {
+	x1, x2, x3, x4 := x[0:16], x[16:32], x[32:48], x[48:64]
+
+	var key [KeySize]byte
+	var t [BlockSize]byte
+	copy(key[:], x[:KeySize])
+	xorBytes(t[:], x3, x4)
+	newCipher(key).Encrypt(s[:], t[:])
+	xorBytes(s[:], s[:], t[:])
+
+	copy(key[:16], s[:])
+	copy(key[16:], x4)
+	newCipher(key).Encrypt(y[:16], x1)
+	xorBytes(y[:16], y[:16], x1)
+
+	for i := range s {
+		key[i] = ^s[i]
+	}
+	copy(key[16:], x3)
+	newCipher(key).Encrypt(y[16:], x2)
+	xorBytes(y[16:], y[16:], x2)
+
+	return s, y
+}
+
+// digest computes belt-hash of section 6.9: every 256-bit block X_i is
+// compressed with h, σ2 gives the next h and the σ1 values are summed
+// into s. The last block is padded with zeros, and the length in bits,
+// s and h are compressed at the end.
+type digest struct {
+	h    [2 * BlockSize]byte
+	s    [BlockSize]byte
+	len  uint64
+	buf  [HashBlockSize]byte
+	nbuf int
+}
+
+// NewHash returns a hash.Hash computing belt-hash.
+func NewHash() hash.Hash {
+	d := new(digest)
+	d.Reset()
+	return d
+}
+
+func (d *digest) Size() int {
+	return HashSize
+}
+
+func (d *digest) BlockSize() int {
+	return HashBlockSize
+}
+
+func (d *digest) Reset() {
+	// h starts as the first 32 bytes of H.
+	copy(d.h[:], H[:2*BlockSize])
+	d.s = [BlockSize]byte{}
+	d.len = 0
+	d.nbuf = 0
+}
+
+func (d *digest) block(p []byte) {
+	var x [4 * BlockSize]byte
+	copy(x[:], p[:HashBlockSize])
+	copy(x[HashBlockSize:], d.h[:])
+	t, h := compress(&x)
+	xorBytes(d.s[:], d.s[:], t[:])
+	d.h = h
+}
+
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	d.len += uint64(n)
+	if d.nbuf > 0 {
+		k := copy(d.buf[d.nbuf:], p)
+		d.nbuf += k
+		p = p[k:]
+		if d.nbuf < HashBlockSize {
+			return n, nil
+		}
+		d.block(d.buf[:])
+		d.nbuf = 0
+	}
+	for len(p) >= HashBlockSize {
+		d.block(p)
+		p = p[HashBlockSize:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// Sum appends the digest of the data written so far to b without
+// changing the state.
+func (d *digest) Sum(b []byte) []byte {
+	d0 := *d
+	if d0.nbuf > 0 {
+		for i := d0.nbuf; i < HashBlockSize; i++ {
+			d0.buf[i] = 0
+		}
+		d0.block(d0.buf[:])
+	}
+
+	// The length is a 128-bit number of bits; the upper half is zero
+	// for any message this hash can take.
+	var x [4 * BlockSize]byte
+	binary.LittleEndian.PutUint64(x[:8], d.len*8)
+	copy(x[BlockSize:], d0.s[:])
+	copy(x[2*BlockSize:], d0.h[:])
+	_, y := compress(&x)
+	return append(b, y[:]...)
+}
@@ -0,0 +1,99 @@
+package belt
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+)
+
+// Examples from STB 34.101.31-2011, appendix A.
+func TestMAC(t *testing.T) {
+	key := decodeKey(t, testKey)
+	data := decodeHex(t, testData)
+
+	tests := []struct {
+		size int
+		want string
+	}{
+		{13, "7260da60138f96c9"},
+		{48, "2dab59771b4b16d0"},
+	}
+	for _, test := range tests {
+		m := NewMAC(key)
+		m.Write(data[:test.size])
+		want := decodeHex(t, test.want)
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("belt-mac of %d bytes = %x, want %s", test.size, got, test.want)
+		}
+		if !VerifyMAC(key, data[:test.size], want) {
+			t.Errorf("VerifyMAC rejected the MAC of %d bytes", test.size)
+		}
+		want[0] ^= 1
+		if VerifyMAC(key, data[:test.size], want) {
+			t.Errorf("VerifyMAC accepted a wrong MAC of %d bytes", test.size)
+		}
+	}
+}
+
+// Examples from STB 34.101.31-2011, appendix A.
+func TestHash(t *testing.T) {
+	data := decodeHex(t, testData)
+
+	tests := []struct {
+		size int
+		want string
+	}{
+		{13, "abef9725d4c5a83597a367d14494cc2542f20f659ddfecc961a3ec550cba8c75"},
+		{32, "749e4c3653aece5e48db4761227742eb6dbe13f4a80f7beff1a9cf8d10ee7786"},
+		{48, "9d02ee446fb6a29fe5c982d4b13af9d3e90861bc4cef27cf306bfb0b174a154a"},
+	}
+	for _, test := range tests {
+		h := NewHash()
+		h.Write(data[:test.size])
+		if got := h.Sum(nil); !bytes.Equal(got, decodeHex(t, test.want)) {
+			t.Errorf("belt-hash of %d bytes = %x, want %s", test.size, got, test.want)
+		}
+	}
+}
+
+// TestStreaming checks that the results do not depend on how the data is
+// split between writes, and that Sum does not change the state.
+func TestStreaming(t *testing.T) {
+	data := make([]byte, 5*BlockSize+3)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+
+	for _, h := range []struct {
+		name string
+		new  func() hash.Hash
+	}{
+		{"belt-mac", func() hash.Hash { return NewMAC(decodeKey(t, testKey)) }},
+		{"belt-hash", NewHash},
+	} {
+		for size := 0; size <= len(data); size++ {
+			whole := h.new()
+			whole.Write(data[:size])
+			want := whole.Sum(nil)
+
+			split := h.new()
+			for i := 0; i < size; i += 7 {
+				end := i + 7
+				if end > size {
+					end = size
+				}
+				split.Write(data[i:end])
+				split.Sum(nil)
+			}
+			if got := split.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%s: %d bytes written in pieces = %x, want %x", h.name, size, got, want)
+			}
+
+			split.Reset()
+			split.Write(data[:size])
+			if got := split.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%s: %d bytes after Reset = %x, want %x", h.name, size, got, want)
+			}
+		}
+	}
+}
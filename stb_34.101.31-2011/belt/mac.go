@@ -0,0 +1,100 @@
+package belt
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+)
+
+// MACSize is the size of a belt-mac value in bytes.
+const MACSize = 8
+
+// mac computes belt-mac of section 7.5: s is chained through the
+// encryption of every block but the last one, which is XORed with a
+// function of r = F(0) that tells a full final block from a padded one.
+type mac struct {
+	b    cipher.Block
+	r    [BlockSize]byte
+	s    [BlockSize]byte
+	buf  [BlockSize]byte
+	nbuf int
+}
+
+// NewMAC returns a hash.Hash computing belt-mac with the key. Sum
+// appends the 64-bit MAC.
+func NewMAC(key [KeySize]byte) hash.Hash {
+	m := &mac{b: NewCipher(key)}
+	m.b.Encrypt(m.r[:], m.r[:])
+	return m
+}
+
+func (m *mac) Size() int {
+	return MACSize
+}
+
+func (m *mac) BlockSize() int {
+	return BlockSize
+}
+
+func (m *mac) Reset() {
+	m.s = [BlockSize]byte{}
+	m.nbuf = 0
+}
+
+// Write keeps the last block, even a full one, in buf until more data
+// comes, since the final block is treated differently.
+func (m *mac) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for len(p) > 0 {
+		if m.nbuf == BlockSize {
+			xorBytes(m.s[:], m.s[:], m.buf[:])
+			m.b.Encrypt(m.s[:], m.s[:])
+			m.nbuf = 0
+		}
+		k := copy(m.buf[m.nbuf:], p)
+		m.nbuf += k
+		p = p[k:]
+	}
+	return n, nil
+}
+
+func (m *mac) Sum(b []byte) []byte {
+	var u, s [BlockSize]byte
+	r := loadWords(m.r[:])
+	if m.nbuf == BlockSize {
+		// φ1(r) = (r2, r3, r4, r1 ^ r2).
+		storeWords(u[:], [4]uint32{r[1], r[2], r[3], r[0] ^ r[1]})
+		copy(s[:], m.buf[:])
+	} else {
+		// φ2(r) = (r1 ^ r4, r1, r2, r3), and the block is padded with
+		// a one bit and zeros.
+		storeWords(u[:], [4]uint32{r[0] ^ r[3], r[0], r[1], r[2]})
+		copy(s[:], m.buf[:m.nbuf])
+		s[m.nbuf] = 0x80
+	}
+	xorBytes(s[:], s[:], m.s[:])
+	xorBytes(s[:], s[:], u[:])
+	m.b.Encrypt(s[:], s[:])
+	return append(b, s[:MACSize]...)
+}
+
+// VerifyMAC reports whether tag is the belt-mac of data under key.
+func VerifyMAC(key [KeySize]byte, data, tag []byte) bool {
+	m := NewMAC(key)
+	m.Write(data)
+	return subtle.ConstantTimeCompare(m.Sum(nil), tag) == 1
+}
+
+func loadWords(b []byte) (w [4]uint32) {
+	for i := range w {
+		w[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return w
+}
+
+func storeWords(b []byte, w [4]uint32) {
+	for i, x := range w {
+		binary.LittleEndian.PutUint32(b[4*i:], x)
+	}
+}
@@ -0,0 +1,160 @@
+package belt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"testing"
+)
+
+// The key, synchronization message and data of the examples in
+// STB 34.101.31-2011, appendix A.
+const (
+	testKey  = "e9dee72c8f0c0fa62ddb49f46f73964706075316ed247a3739cba38303a98bf6"
+	testIV   = "be32971343fc9a48a02a885f194b09a1"
+	testData = "b194bac80a08f53b366d008e584a5de48504fa9d1bb6c7ac252e72c202fdce0d5be3d61217b96181fe6786ad716b890b"
+)
+
+func testModeVectors(t *testing.T, name string, enc, dec func(cipher.Block) cipher.BlockMode, tests []struct {
+	size int
+	ct   string
+}) {
+	c := NewCipher(decodeKey(t, testKey))
+	for _, test := range tests {
+		pt := decodeHex(t, testData)[:test.size]
+		want := decodeHex(t, test.ct)
+
+		got := make([]byte, len(pt))
+		enc(c).CryptBlocks(got, pt)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: encrypt %d bytes = %x, want %x", name, test.size, got, want)
+		}
+
+		dec(c).CryptBlocks(got, want)
+		if !bytes.Equal(got, pt) {
+			t.Errorf("%s: decrypt %d bytes = %x, want %x", name, test.size, got, pt)
+		}
+	}
+}
+
+func TestECB(t *testing.T) {
+	testModeVectors(t, "belt-ecb", NewECBEncrypter, NewECBDecrypter, []struct {
+		size int
+		ct   string
+	}{
+		{48, "69cca1c93557c9e3d66bc3e0fa88fa6e5f23102ef109710775017f73806da9dc46fb2ed2ce771f26dcb5e5d1569f9ab0"},
+		{47, "69cca1c93557c9e3d66bc3e0fa88fa6e36f00cfed6d1ca1498c12798f4beb2075f23102ef109710775017f73806da9"},
+	})
+}
+
+func TestCBC(t *testing.T) {
+	iv := decodeHex(t, testIV)
+	testModeVectors(t, "belt-cbc",
+		func(b cipher.Block) cipher.BlockMode { return NewCBCEncrypter(b, iv) },
+		func(b cipher.Block) cipher.BlockMode { return NewCBCDecrypter(b, iv) },
+		[]struct {
+			size int
+			ct   string
+		}{
+			{48, "10116efae6ad58ee14852e11da1b8a745cf2480e8d03f1c19492e53ed3a70f60657c1ee8c0e0ae5b58388bf8a68e3309"},
+			{36, "10116efae6ad58ee14852e11da1b8a746a9bbadcaf73f968f875dedc0a44f6b15cf2480e"},
+		})
+}
+
+// TestStealing checks exact round trips of every length, in place and
+// with the message split between two calls.
+func TestStealing(t *testing.T) {
+	c := NewCipher(decodeKey(t, testKey))
+	iv := decodeHex(t, testIV)
+	modes := []struct {
+		name     string
+		enc, dec func() cipher.BlockMode
+	}{
+		{"belt-ecb", func() cipher.BlockMode { return NewECBEncrypter(c) }, func() cipher.BlockMode { return NewECBDecrypter(c) }},
+		{"belt-cbc", func() cipher.BlockMode { return NewCBCEncrypter(c, iv) }, func() cipher.BlockMode { return NewCBCDecrypter(c, iv) }},
+	}
+
+	pt := make([]byte, 5*BlockSize)
+	for i := range pt {
+		pt[i] = byte(i * 11)
+	}
+	for _, mode := range modes {
+		for size := BlockSize; size <= len(pt); size++ {
+			want := make([]byte, size)
+			mode.enc().CryptBlocks(want, pt[:size])
+
+			buf := append([]byte{}, pt[:size]...)
+			if size >= 2*BlockSize {
+				enc := mode.enc()
+				enc.CryptBlocks(buf[:BlockSize], buf[:BlockSize])
+				enc.CryptBlocks(buf[BlockSize:], buf[BlockSize:])
+				if !bytes.Equal(buf, want) {
+					t.Errorf("%s: %d bytes encrypted in two calls = %x, want %x", mode.name, size, buf, want)
+				}
+			}
+
+			copy(buf, want)
+			mode.dec().CryptBlocks(buf, buf)
+			if !bytes.Equal(buf, pt[:size]) {
+				t.Errorf("%s: %d bytes decrypted in place = %x, want %x", mode.name, size, buf, pt[:size])
+			}
+		}
+	}
+}
+
+func testStreamVectors(t *testing.T, name string, enc, dec func(cipher.Block, []byte) cipher.Stream, want string) {
+	c := NewCipher(decodeKey(t, testKey))
+	iv := decodeHex(t, testIV)
+	pt := decodeHex(t, testData)
+	ct := decodeHex(t, want)
+
+	for _, size := range []int{len(pt), 2*BlockSize + 5, 7} {
+		got := make([]byte, size)
+		enc(c, iv).XORKeyStream(got, pt[:size])
+		if !bytes.Equal(got, ct[:size]) {
+			t.Errorf("%s: encrypt %d bytes = %x, want %x", name, size, got, ct[:size])
+		}
+
+		// Decrypt in pieces that do not line up with the blocks.
+		s := dec(c, iv)
+		for i := 0; i < size; i += 5 {
+			end := i + 5
+			if end > size {
+				end = size
+			}
+			s.XORKeyStream(got[i:end], got[i:end])
+		}
+		if !bytes.Equal(got, pt[:size]) {
+			t.Errorf("%s: decrypt %d bytes = %x, want %x", name, size, got, pt[:size])
+		}
+	}
+}
+
+func TestCFB(t *testing.T) {
+	testStreamVectors(t, "belt-cfb", NewCFBEncrypter, NewCFBDecrypter,
+		"c31e490a90efa374626cc99e4b7b8540a6e48685464a5a06849c9ca769a1b0ae55c2cc5939303ec832dd2fe16c8e5a1b")
+}
+
+func TestCTR(t *testing.T) {
+	testStreamVectors(t, "belt-ctr", NewCTR, NewCTR,
+		"52c9af96ff50f64435fc43def56bd797d5b5b1ff79fb41257ab9cdf6e63e81f8f00341473eae409833622de05213773a")
+}
+
+func TestCTRCounterWraps(t *testing.T) {
+	c := NewCipher(decodeKey(t, testKey))
+
+	// With S the decryption of all ones, s = F(S) wraps to zero on the
+	// first increment, so the first block of gamma is F(0).
+	var ones, zero, want [BlockSize]byte
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	iv := make([]byte, BlockSize)
+	c.Decrypt(iv, ones[:])
+	c.Encrypt(want[:], zero[:])
+
+	gamma := make([]byte, BlockSize)
+	NewCTR(c, iv).XORKeyStream(gamma, gamma)
+	if !bytes.Equal(gamma, want[:]) {
+		t.Errorf("gamma after the counter wraps = %x, want %x", gamma, want)
+	}
+}
@@ -0,0 +1,3 @@
+module six_nine/stb_34.101.31-2011
+
+go 1.20
@@ -0,0 +1,188 @@
+// Command gostsum prints or checks GOST R 34.11-2012 (Streebog) digests.
+// Its output and manifests use the format of sha256sum:
+//
+//	gostsum -a 256 file1 file2 > MANIFEST
+//	gostsum -a 256 -c MANIFEST
+//
+// With no files, or when a file is "-", standard input is read.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"six_nine/streebog/streebog"
+)
+
+func newHash(bits int) hash.Hash {
+	if bits == 256 {
+		return streebog.New256()
+	}
+	return streebog.New512()
+}
+
+func sumFile(bits int, name string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	h := newHash(bits)
+	if _, err := io.Copy(h, bufio.NewReader(r)); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// parseLine splits a manifest line into the digest and the file name. A
+// '*' before the name marks binary mode in sha256sum and is ignored.
+func parseLine(line string, size int) (digest []byte, name string, ok bool) {
+	sum, name, found := strings.Cut(line, " ")
+	if !found || len(sum) != 2*size || name == "" {
+		return nil, "", false
+	}
+	if name[0] != ' ' && name[0] != '*' {
+		return nil, "", false
+	}
+	name = name[1:]
+
+	digest, err := hex.DecodeString(sum)
+	if err != nil || name == "" {
+		return nil, "", false
+	}
+
+	return digest, name, true
+}
+
+// check verifies the files listed in manifest and reports the result for
+// each of them to w. It returns the number of files that failed and the
+// number of lines it could not parse.
+func check(bits int, manifest io.Reader, w, errw io.Writer) (failed, malformed int, err error) {
+	size := bits / 8
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		want, name, ok := parseLine(line, size)
+		if !ok {
+			malformed++
+			continue
+		}
+
+		got, err := sumFile(bits, name)
+		switch {
+		case err != nil:
+			fmt.Fprintln(errw, "gostsum:", err)
+			fmt.Fprintf(w, "%s: FAILED open or read\n", name)
+			failed++
+		case !bytes.Equal(got, want):
+			fmt.Fprintf(w, "%s: FAILED\n", name)
+			failed++
+		default:
+			fmt.Fprintf(w, "%s: OK\n", name)
+		}
+	}
+
+	return failed, malformed, scanner.Err()
+}
+
+// checkManifest runs check on the named manifest, or on standard input
+// for "-", and reports a summary of the problems to errw. The manifest is
+// closed before it returns. It returns the exit status.
+func checkManifest(bits int, name string, w, errw io.Writer) int {
+	var manifest io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(errw, "gostsum:", err)
+			return 1
+		}
+		defer f.Close()
+		manifest = f
+	}
+
+	status := 0
+	failed, malformed, err := check(bits, manifest, w, errw)
+	if err != nil {
+		fmt.Fprintln(errw, "gostsum:", err)
+		status = 1
+	}
+	if malformed > 0 {
+		fmt.Fprintf(errw, "gostsum: WARNING: %d line(s) improperly formatted\n", malformed)
+		status = 1
+	}
+	if failed > 0 {
+		fmt.Fprintf(errw, "gostsum: WARNING: %d computed checksum(s) did NOT match\n", failed)
+		status = 1
+	}
+
+	return status
+}
+
+// run is the command without the process around it: it parses args,
+// writes to w and errw and returns the exit status.
+func run(args []string, w, errw io.Writer) int {
+	flags := flag.NewFlagSet("gostsum", flag.ContinueOnError)
+	flags.SetOutput(errw)
+	bits := flags.Int("a", 512, "digest length in bits, 256 or 512")
+	checkMode := flags.Bool("c", false, "read digests from the files and check them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: gostsum [-a 256|512] [-c] [file ...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+
+	if *bits != 256 && *bits != 512 {
+		fmt.Fprintf(errw, "gostsum: unsupported digest length %d\n", *bits)
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, name := range files {
+		if *checkMode {
+			if s := checkManifest(*bits, name, w, errw); s != 0 {
+				status = s
+			}
+			continue
+		}
+
+		sum, err := sumFile(*bits, name)
+		if err != nil {
+			fmt.Fprintln(errw, "gostsum:", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(w, "%x  %s\n", sum, name)
+	}
+
+	return status
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
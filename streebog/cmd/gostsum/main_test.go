@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good")
+	bad := filepath.Join(dir, "bad")
+	missing := filepath.Join(dir, "missing")
+	for _, name := range []string{good, bad} {
+		if err := os.WriteFile(name, []byte("012345678901234567890123456789012345678901234567890123456789012"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Digest of M1 from GOST R 34.11-2012, appendix A.
+	const sum = "9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500"
+	got, err := sumFile(256, good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%x", got) != sum {
+		t.Fatalf("sumFile = %x, want %s", got, sum)
+	}
+
+	manifest := sum + "  " + good + "\n" +
+		strings.Repeat("0", len(sum)) + " *" + bad + "\n" +
+		sum + "  " + missing + "\n" +
+		"not a digest line\n"
+
+	var out bytes.Buffer
+	failed, malformed, err := check(256, strings.NewReader(manifest), &out, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 2 || malformed != 1 {
+		t.Errorf("check = %d failed, %d malformed, want 2 and 1", failed, malformed)
+	}
+
+	want := good + ": OK\n" + bad + ": FAILED\n" + missing + ": FAILED open or read\n"
+	if out.String() != want {
+		t.Errorf("check output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "m1")
+	if err := os.WriteFile(file, []byte("012345678901234567890123456789012345678901234567890123456789012"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const sum = "9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500"
+
+	var out, errOut bytes.Buffer
+	if status := run([]string{"-a", "256", file}, &out, &errOut); status != 0 {
+		t.Fatalf("run = %d, stderr: %s", status, errOut.String())
+	}
+	if want := sum + "  " + file + "\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	// The printed line is a manifest that checks.
+	manifest := filepath.Join(dir, "MANIFEST")
+	if err := os.WriteFile(manifest, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if status := run([]string{"-a", "256", "-c", manifest}, &out, &errOut); status != 0 {
+		t.Fatalf("run -c = %d, stderr: %s", status, errOut.String())
+	}
+	if want := file + ": OK\n"; out.String() != want {
+		t.Errorf("check output = %q, want %q", out.String(), want)
+	}
+
+	// A mismatch, given twice, fails with a warning for each manifest.
+	if err := os.WriteFile(file, []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	errOut.Reset()
+	if status := run([]string{"-a", "256", "-c", manifest, manifest}, &out, &errOut); status != 1 {
+		t.Errorf("run -c after a change = %d, want 1", status)
+	}
+	if want := strings.Repeat(file+": FAILED\n", 2); out.String() != want {
+		t.Errorf("check output = %q, want %q", out.String(), want)
+	}
+	if n := strings.Count(errOut.String(), "did NOT match"); n != 2 {
+		t.Errorf("stderr has %d mismatch warnings, want 2:\n%s", n, errOut.String())
+	}
+
+	for _, args := range [][]string{{"-a", "384", file}, {"-x"}} {
+		if status := run(args, &out, &bytes.Buffer{}); status != 2 {
+			t.Errorf("run(%q) = %d, want 2", args, status)
+		}
+	}
+	if status := run([]string{filepath.Join(dir, "missing")}, &out, &bytes.Buffer{}); status != 1 {
+		t.Errorf("run of a missing file = %d, want 1", status)
+	}
+}
@@ -1,19 +1,16 @@
 package main
 
 import (
-    "fmt"
-    "six_nine/streebog/streebog"
+	"fmt"
+	"six_nine/streebog/streebog"
 )
 
 func main() {
 
-    msg := []byte{0x32,0x31,0x30,0x39,0x38,0x37,0x36,0x35,0x34,0x33,0x32,0x31,0x30,0x39,0x38,0x37,
-                  0x36,0x35,0x34,0x33,0x32,0x31,0x30,0x39,0x38,0x37,0x36,0x35,0x34,0x33,0x32,0x31,
-                  0x30,0x39,0x38,0x37,0x36,0x35,0x34,0x33,0x32,0x31,0x30,0x39,0x38,0x37,0x36,0x35,
-                  0x34,0x33,0x32,0x31,0x30,0x39,0x38,0x37,0x36,0x35,0x34,0x33,0x32,0x31,0x30}
+	// Message M1 from GOST R 34.11-2012, appendix A.
+	msg := []byte("012345678901234567890123456789012345678901234567890123456789012")
 
-    res := streebog.Hash(msg, 512)
+	fmt.Printf("%x\n", streebog.Hash(msg, 512))
+	fmt.Printf("%x\n", streebog.Hash(msg, 256))
 
-    fmt.Println(res)
-    
 }
@@ -0,0 +1,136 @@
+package streebog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// referenceDigests are digests computed by other Streebog implementations,
+// keyed by the hex of the message.
+var referenceDigests = map[string][2]string{
+	// M1 and M2 from GOST R 34.11-2012, appendix A.
+	vectors[0].message: {vectors[0].sum256, vectors[0].sum512},
+	vectors[1].message: {vectors[1].sum256, vectors[1].sum512},
+	// The empty message.
+	"": {
+		"3f539a213e97c802cc229d474c6aa32a825a360b2a933a949fd925208d9ce1bb",
+		"8e945da209aa869f0455928529bcae4679e9873ab707b55315f56ceb98bef0a7362f715528356ee83cda5f2aac4c6ad2ba3a715c1bcd81cb8e9f90bf4c1c1a8a",
+	},
+	// "The quick brown fox jumps over the lazy dog".
+	hex.EncodeToString([]byte("The quick brown fox jumps over the lazy dog")): {
+		"3e7dea7f2384b6c5a3d0e24aaa29c05e89ddd762145030ec22c71a6db8b2c1f4",
+		"d2b793a0bb6cb5904828b5b6dcfb443bb8f33efc06ad09368878ae4cdc8245b97e60802469bed1e7c21a64ff0b179a6a1e0bb74d92965450a0adab69162c00fe",
+	},
+	// 64, 65 and 128 bytes of 0xff, computed with Nettle 3.8: one full
+	// block, a full block and one byte, and two blocks whose sum carries
+	// through every byte of sigma.
+	strings.Repeat("ff", 64): {
+		"964a5ab60286f106288743e2fe1a422d160898ca1bd535e831aa500cfe34d7e8",
+		"41629de677d7e8090c3cd70affe3300d1e1cfba2db97945ec37feb4e1375bc02a53f00370b7d715b07f37f93cac844efadbfd1b85f9ddae3de9656c0e95affc7",
+	},
+	strings.Repeat("ff", 65): {
+		"a363df25cb169ab7b2cc691ddd778f75b10394e803d75b1bd167441a09b9f9ba",
+		"b9690cbd837b4331b75cdff6a0c452f0978177e57f799a2c7ade51a0cad2b08137fac89c2ef3637ead559560614cd02f5f2d3998bedae9a312dabd5c5baf09e8",
+	},
+	strings.Repeat("ff", 128): {
+		"4749bfc37b7ddad7c745dc2da1fb22619f70154c064ae3b6cb34bc2b2c0827c1",
+		"90a161d12ad309498d3fe5d48202d8a4e9c406d6a264aeab258ac5ecc37a7962aaf9587a5abb09b6bb81ec4b3752a3ff5a838ef175be5772056bc5fe54fcfc7e",
+	},
+}
+
+// referenceAdd adds the little-endian numbers a and b modulo 2^512.
+func referenceAdd(a, b []byte) []byte {
+	res := make([]byte, BlockSize)
+	var carry uint16
+	for i := range res {
+		sum := carry
+		if i < len(a) {
+			sum += uint16(a[i])
+		}
+		if i < len(b) {
+			sum += uint16(b[i])
+		}
+		res[i] = byte(sum)
+		carry = sum >> 8
+	}
+	return res
+}
+
+// referenceHash follows GOST R 34.11-2012, section 8, step by step with
+// referenceG and byte strings.
+func referenceHash(msg []byte, outLen int) []byte {
+	h := make([]byte, BlockSize)
+	if outLen == 256 {
+		for i := range h {
+			h[i] = 0x01
+		}
+	}
+	n := make([]byte, BlockSize)
+	sigma := make([]byte, BlockSize)
+	zero := make([]byte, BlockSize)
+
+	for len(msg) >= BlockSize {
+		m := msg[:BlockSize]
+		h = referenceG(n, m, h)
+		n = referenceAdd(n, []byte{0x00, 0x02})
+		sigma = referenceAdd(sigma, m)
+		msg = msg[BlockSize:]
+	}
+
+	m := make([]byte, BlockSize)
+	copy(m, msg)
+	m[len(msg)] = 0x01
+	h = referenceG(n, m, h)
+	bitLen := make([]byte, 8)
+	binary.LittleEndian.PutUint64(bitLen, uint64(8*len(msg)))
+	n = referenceAdd(n, bitLen)
+	sigma = referenceAdd(sigma, m)
+	h = referenceG(zero, n, h)
+	h = referenceG(zero, sigma, h)
+
+	return h[BlockSize-outLen/8:]
+}
+
+// FuzzStribog compares both digest lengths with referenceHash, with a hash
+// written in two parts and, for the messages in referenceDigests, with the
+// known digests.
+func FuzzStribog(f *testing.F) {
+	for msg := range referenceDigests {
+		b, _ := hex.DecodeString(msg)
+		f.Add(b, len(b)/2)
+	}
+	for _, n := range []int{1, 63, 64, 65, 127, 128, 200} {
+		f.Add(bytes.Repeat([]byte{0xff}, n), n/3)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, split int) {
+		if split < 0 || split > len(data) {
+			split = len(data) / 2
+		}
+		known, isKnown := referenceDigests[hex.EncodeToString(data)]
+
+		for i, outLen := range []int{256, 512} {
+			want := referenceHash(data, outLen)
+			if isKnown && hex.EncodeToString(want) != known[i] {
+				t.Fatalf("%d: referenceHash(%x) = %x, want %s", outLen, data, want, known[i])
+			}
+
+			if got := Hash(data, outLen); !bytes.Equal(got, want) {
+				t.Fatalf("%d: Hash(%x) = %x, want %x", outLen, data, got, want)
+			}
+
+			s := New512()
+			if outLen == 256 {
+				s = New256()
+			}
+			s.Write(data[:split])
+			s.Write(data[split:])
+			if got := s.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("%d: write split at %d: Sum(%x) = %x, want %x", outLen, split, data, got, want)
+			}
+		}
+	})
+}
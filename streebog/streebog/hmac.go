@@ -0,0 +1,16 @@
+package streebog
+
+import (
+	"crypto/hmac"
+	"hash"
+)
+
+// NewHMAC256 returns HMAC_GOSTR3411_2012_256 from RFC 7836, section 4.1.
+func NewHMAC256(key []byte) hash.Hash {
+	return hmac.New(func() hash.Hash { return New256() }, key)
+}
+
+// NewHMAC512 returns HMAC_GOSTR3411_2012_512 from RFC 7836, section 4.1.
+func NewHMAC512(key []byte) hash.Hash {
+	return hmac.New(func() hash.Hash { return New512() }, key)
+}
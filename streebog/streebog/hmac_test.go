@@ -0,0 +1,31 @@
+package streebog
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+)
+
+// Examples from RFC 7836, section 4.1.
+func TestHMAC(t *testing.T) {
+	key := decodeHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	data := decodeHex(t, "0126bdb87800af214341456563780100")
+
+	tests := []struct {
+		name string
+		new  func([]byte) hash.Hash
+		want string
+	}{
+		{"HMAC_GOSTR3411_2012_256", NewHMAC256,
+			"a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9"},
+		{"HMAC_GOSTR3411_2012_512", NewHMAC512,
+			"a59bab22ecae19c65fbde6e5f4e9f5d8549d31f037f9df9b905500e171923a773d5f1530f2ed7e964cb2eedc29e9ad2f3afe93b2814f79f5000ffc0366c251e6"},
+	}
+	for _, test := range tests {
+		mac := test.new(key)
+		mac.Write(data)
+		if got := mac.Sum(nil); !bytes.Equal(got, decodeHex(t, test.want)) {
+			t.Errorf("%s = %x, want %s", test.name, got, test.want)
+		}
+	}
+}
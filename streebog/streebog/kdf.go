@@ -0,0 +1,96 @@
+package streebog
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+// KDF256 is KDF_GOSTR3411_2012_256 from R 50.1.113-2016 (RFC 7836,
+// section 4.4): HMAC-Streebog-256 of 0x01 | label | 0x00 | seed | 0x0100
+// keyed with key. It yields a 256-bit key.
+func KDF256(key, label, seed []byte) []byte {
+	mac := NewHMAC256(key)
+	mac.Write([]byte{0x01})
+	mac.Write(label)
+	mac.Write([]byte{0x00})
+	mac.Write(seed)
+	mac.Write([]byte{0x01, 0x00})
+	return mac.Sum(nil)
+}
+
+// KDFTree256 is KDF_TREE_GOSTR3411_2012_256 from R 50.1.113-2016 (RFC 7836,
+// section 4.5). It derives length bytes as K(1) | K(2) | ..., where
+// K(i) = HMAC-Streebog-256(key, [i] | label | 0x00 | seed | [L]), [i] is
+// the counter in r bytes, from 1 to 4, and [L] is the output length in
+// bits, both big-endian.
+func KDFTree256(key, label, seed []byte, length, r int) ([]byte, error) {
+	if r < 1 || r > 4 {
+		return nil, errors.New("streebog: KDF_TREE counter must be 1 to 4 bytes long")
+	}
+	blocks := (length + 31) / 32
+	if length <= 0 || uint64(blocks) >= 1<<(8*uint(r)) {
+		return nil, errors.New("streebog: KDF_TREE output length out of range")
+	}
+
+	var lBuf [8]byte
+	binary.BigEndian.PutUint64(lBuf[:], uint64(length)*8)
+	l := lBuf[(bits.LeadingZeros64(uint64(length)*8))/8:]
+
+	var iBuf [4]byte
+	out := make([]byte, 0, blocks*32)
+	for i := 1; i <= blocks; i++ {
+		binary.BigEndian.PutUint32(iBuf[:], uint32(i))
+		mac := NewHMAC256(key)
+		mac.Write(iBuf[4-r:])
+		mac.Write(label)
+		mac.Write([]byte{0x00})
+		mac.Write(seed)
+		mac.Write(l)
+		out = mac.Sum(out)
+	}
+
+	return out[:length], nil
+}
+
+// PBKDF2 derives a keyLen-byte key from password and salt with PBKDF2
+// (RFC 8018) over HMAC-Streebog-512, as R 50.1.111-2016 specifies. The
+// iteration count and the key length must be at least 1.
+func PBKDF2(password, salt []byte, iter, keyLen int) ([]byte, error) {
+	if iter < 1 {
+		return nil, errors.New("streebog: PBKDF2 iteration count must be positive")
+	}
+	var prf hash.Hash = NewHMAC512(password)
+	hLen := prf.Size()
+	blocks := (keyLen + hLen - 1) / hLen
+	if keyLen < 1 || uint64(blocks) > 1<<32-1 {
+		return nil, errors.New("streebog: PBKDF2 key length out of range")
+	}
+
+	var iBuf [4]byte
+	out := make([]byte, 0, blocks*hLen)
+	u := make([]byte, hLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(iBuf[:], uint32(block))
+		prf.Write(iBuf[:])
+
+		start := len(out)
+		out = prf.Sum(out)
+		t := out[start:]
+		copy(u, t)
+
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return out[:keyLen], nil
+}
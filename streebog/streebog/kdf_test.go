@@ -0,0 +1,69 @@
+package streebog
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Examples from RFC 7836, sections 4.4 and 4.5.
+func TestKDF256(t *testing.T) {
+	key := decodeHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	label := decodeHex(t, "26bdb878")
+	seed := decodeHex(t, "af21434145656378")
+
+	want := decodeHex(t, "a1aa5f7de402d7b3d323f2991c8d4534013137010a83754fd0af6d7cd4922ed9")
+	if got := KDF256(key, label, seed); !bytes.Equal(got, want) {
+		t.Errorf("KDF256 = %x, want %x", got, want)
+	}
+
+	want = decodeHex(t, "22b6837845c6bef65ea71672b265831086d3c76aebe6dae91cad51d83f79d16b"+
+		"074c9330599d7f8d712fca54392f4ddde93751206b3584c8f43f9e6dc51531f9")
+	got, err := KDFTree256(key, label, seed, 64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("KDFTree256 = %x, want %x", got, want)
+	}
+
+	for _, test := range []struct{ length, r int }{{64, 0}, {64, 5}, {0, 1}, {256 * 32, 1}} {
+		if _, err := KDFTree256(key, label, seed, test.length, test.r); err == nil {
+			t.Errorf("KDFTree256(length=%d, r=%d) did not fail", test.length, test.r)
+		}
+	}
+}
+
+// Examples from R 50.1.111-2016, appendix A.
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		password string
+		salt     string
+		iter     int
+		keyLen   int
+		want     string
+	}{
+		{"password", "salt", 1, 64,
+			"64770af7f748c3b1c9ac831dbcfd85c26111b30a8a657ddc3056b80ca73e040d2854fd36811f6d825cc4ab66ec0a68a490a9e5cf5156b3a2b7eecddbf9a16b47"},
+		{"password", "salt", 2, 64,
+			"5a585bafdfbb6e8830d6d68aa3b43ac00d2e4aebce01c9b31c2caed56f0236d4d34b2b8fbd2c4e89d54d46f50e47d45bbac301571743119e8d3c42ba66d348de"},
+		{"password", "salt", 4096, 64,
+			"e52deb9a2d2aaff4e2ac9d47a41f34c20376591c67807f0477e32549dc341bc7867c09841b6d58e29d0347c996301d55df0d34e47cf68f4e3c2cdaf1d9ab86c3"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 100,
+			"b2d8f1245fc4d29274802057e4b54e0a0753aa22fc53760b301cf008679e58fe4bee9addcae99ba2b0b20f431a9c5e50f395c89387d0945aedeca6eb4015dfc2bd2421ee9bb71183ba882ceebfef259f33f9e27dc6178cb89dc37428cf9cc52a2baa2d3a"},
+	}
+	for _, test := range tests {
+		got, err := PBKDF2([]byte(test.password), []byte(test.salt), test.iter, test.keyLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, decodeHex(t, test.want)) {
+			t.Errorf("PBKDF2(%q, %q, %d) = %x, want %s", test.password, test.salt, test.iter, got, test.want)
+		}
+	}
+
+	for _, test := range []struct{ iter, keyLen int }{{0, 64}, {-1, 64}, {1, 0}, {1, -1}} {
+		if _, err := PBKDF2([]byte("password"), []byte("salt"), test.iter, test.keyLen); err == nil {
+			t.Errorf("PBKDF2(iter=%d, keyLen=%d) did not fail", test.iter, test.keyLen)
+		}
+	}
+}
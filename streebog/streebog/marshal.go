@@ -0,0 +1,73 @@
+package streebog
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The saved state starts with a tag naming the algorithm and the version
+// of the format, followed by h, N and sigma as little-endian words, the
+// buffered input and its length.
+const (
+	magic256      = "streebog256\x01"
+	magic512      = "streebog512\x01"
+	marshaledSize = len(magic512) + 3*8*8 + BlockSize + 1
+)
+
+func appendWords(b []byte, x *[8]uint64) []byte {
+	for _, w := range x {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b
+}
+
+func consumeWords(b []byte, x *[8]uint64) []byte {
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint64(b)
+		b = b[8:]
+	}
+	return b
+}
+
+// MarshalBinary saves the intermediate state of the hash, so that it can
+// be restored with UnmarshalBinary, possibly in another process.
+func (s *Stribog) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, marshaledSize)
+	if s.size == 256/8 {
+		b = append(b, magic256...)
+	} else {
+		b = append(b, magic512...)
+	}
+	b = appendWords(b, &s.h)
+	b = appendWords(b, &s.n)
+	b = appendWords(b, &s.sigma)
+	b = append(b, s.buf[:]...)
+	b = append(b, byte(s.nbuf))
+	return b, nil
+}
+
+// UnmarshalBinary restores a state saved by MarshalBinary. The state must
+// come from a hash of the same digest length.
+func (s *Stribog) UnmarshalBinary(b []byte) error {
+	magic := magic512
+	if s.size == 256/8 {
+		magic = magic256
+	}
+	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
+		return errors.New("streebog: invalid hash state identifier")
+	}
+	if len(b) != marshaledSize {
+		return errors.New("streebog: invalid hash state size")
+	}
+	if int(b[len(b)-1]) >= BlockSize {
+		return errors.New("streebog: invalid hash state")
+	}
+
+	b = b[len(magic):]
+	b = consumeWords(b, &s.h)
+	b = consumeWords(b, &s.n)
+	b = consumeWords(b, &s.sigma)
+	b = b[copy(s.buf[:], b):]
+	s.nbuf = int(b[0])
+	return nil
+}
@@ -0,0 +1,51 @@
+package streebog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	msg := make([]byte, 2*BlockSize+40)
+	for i := range msg {
+		msg[i] = byte(3 * i)
+	}
+
+	for _, outLen := range []int{256, 512} {
+		newHash := New512
+		if outLen == 256 {
+			newHash = New256
+		}
+		want := Hash(msg, outLen)
+
+		for _, split := range []int{0, 1, BlockSize, BlockSize + 17, len(msg)} {
+			s := newHash()
+			s.Write(msg[:split])
+			state, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := newHash()
+			if err := r.UnmarshalBinary(state); err != nil {
+				t.Fatalf("%d: UnmarshalBinary after %d bytes: %v", outLen, split, err)
+			}
+			r.Write(msg[split:])
+			if got := r.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%d: resumed after %d bytes: Sum() = %x, want %x", outLen, split, got, want)
+			}
+		}
+	}
+
+	state, _ := New256().MarshalBinary()
+	if err := New512().UnmarshalBinary(state); err == nil {
+		t.Error("a 512-bit hash accepted the state of a 256-bit one")
+	}
+	if err := New256().UnmarshalBinary(state[:len(state)-1]); err == nil {
+		t.Error("UnmarshalBinary accepted a truncated state")
+	}
+	state[len(state)-1] = BlockSize
+	if err := New256().UnmarshalBinary(state); err == nil {
+		t.Error("UnmarshalBinary accepted a full buffer")
+	}
+}
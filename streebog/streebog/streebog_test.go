@@ -0,0 +1,272 @@
+package streebog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(t testing.TB, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Messages M1 and M2 from GOST R 34.11-2012, appendix A, written as byte
+// strings (RFC 6986, section 10); digests are in the same byte order.
+var vectors = []struct {
+	name    string
+	message string
+	sum256  string
+	sum512  string
+}{
+	{
+		"M1",
+		"303132333435363738393031323334353637383930313233343536373839303132333435363738393031323334353637383930313233343536373839303132",
+		"9d151eefd8590b89daa6ba6cb74af9275dd051026bb149a452fd84e5e57b5500",
+		"1b54d01a4af5b9d5cc3d86d68d285462b19abc2475222f35c085122be4ba1ffa00ad30f8767b3a82384c6574f024c311e2a481332b08ef7f41797891c1646f48",
+	},
+	{
+		"M2",
+		"d1e520e2e5f2f0e82c20d1f2f0e8e1eee6e820e2edf3f6e82c20e2e5fef2fa20f120eceef0ff20f1f2f0e5ebe0ece820ede020f5f0e0e1f0fbff20efebfaeafb20c8e3eef0e5e2fb",
+		"9dd2fe4e90409e5da87f53976d7405b0c0cac628fc669a741d50063c557e8f50",
+		"1e88e62226bfca6f9994f1f2d51569e0daf8475a3b0fe61a5300eee46d961376035fe83549ada2b8620fcd7c496ce5b33f0cb9dddc2b6460143b03dabac9fb28",
+	},
+}
+
+func TestHash(t *testing.T) {
+	for _, v := range vectors {
+		msg := decodeHex(t, v.message)
+		if got := Hash(msg, 256); !bytes.Equal(got, decodeHex(t, v.sum256)) {
+			t.Errorf("%s: Hash(256) = %x, want %s", v.name, got, v.sum256)
+		}
+		if got := Hash(msg, 512); !bytes.Equal(got, decodeHex(t, v.sum512)) {
+			t.Errorf("%s: Hash(512) = %x, want %s", v.name, got, v.sum512)
+		}
+	}
+}
+
+func TestStribog(t *testing.T) {
+	for _, v := range vectors {
+		msg := decodeHex(t, v.message)
+		for _, h := range []struct {
+			s    *Stribog
+			want string
+		}{
+			{New256(), v.sum256},
+			{New512(), v.sum512},
+		} {
+			h.s.Write(msg)
+			if got := h.s.Sum(nil); !bytes.Equal(got, decodeHex(t, h.want)) {
+				t.Errorf("%s: Sum() = %x, want %s", v.name, got, h.want)
+			}
+		}
+	}
+}
+
+func TestStribogStreaming(t *testing.T) {
+	msg := make([]byte, 3*BlockSize+17)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+
+	for _, outLen := range []int{256, 512} {
+		s := New512()
+		if outLen == 256 {
+			s = New256()
+		}
+		for i := 0; i < len(msg); i += 7 {
+			end := i + 7
+			if end > len(msg) {
+				end = len(msg)
+			}
+			s.Write(msg[i:end])
+
+			// Sum must not disturb the running state.
+			if got, want := s.Sum(nil), Hash(msg[:end], outLen); !bytes.Equal(got, want) {
+				t.Fatalf("%d: Sum after %d bytes = %x, want %x", outLen, end, got, want)
+			}
+		}
+
+		s.Reset()
+		s.Write(msg)
+		if got, want := s.Sum(nil), Hash(msg, outLen); !bytes.Equal(got, want) {
+			t.Errorf("%d: Sum after Reset = %x, want %x", outLen, got, want)
+		}
+	}
+}
+
+// The byte-oriented transformations of the standard, kept as a reference
+// for compress.
+
+// referenceX XORs a into k; both are 64 bytes long.
+func referenceX(k, a []byte) []byte {
+	res := make([]byte, len(k))
+	for i := range res {
+		res[i] = k[i] ^ a[i]
+	}
+	return res
+}
+
+func referenceS(a []byte) []byte {
+	var res []byte
+	for i := 0; i < len(a); i++ {
+		res = append(res, PI[a[i]])
+	}
+	return res
+}
+
+var referenceTau = []byte{
+	0, 8, 16, 24, 32, 40, 48, 56,
+	1, 9, 17, 25, 33, 41, 49, 57,
+	2, 10, 18, 26, 34, 42, 50, 58,
+	3, 11, 19, 27, 35, 43, 51, 59,
+	4, 12, 20, 28, 36, 44, 52, 60,
+	5, 13, 21, 29, 37, 45, 53, 61,
+	6, 14, 22, 30, 38, 46, 54, 62,
+	7, 15, 23, 31, 39, 47, 55, 63,
+}
+
+func referenceP(a []byte) []byte {
+	var res []byte
+	for i := 0; i < len(a); i++ {
+		res = append(res, a[referenceTau[i]])
+	}
+	return res
+}
+
+// referenceL multiplies every 64-bit word of a, read little-endian, by
+// the matrix A. The most significant bit of a word selects A[0].
+func referenceL(a []byte) []byte {
+	res := make([]byte, 64)
+	for i := 0; i < 64; i += 8 {
+		x := binary.LittleEndian.Uint64(a[i:])
+		var y uint64
+		for j := 0; j < 64; j++ {
+			if (x>>(63-j))&1 == 1 {
+				y ^= A[j]
+			}
+		}
+		binary.LittleEndian.PutUint64(res[i:], y)
+	}
+	return res
+}
+
+func referenceKeySchedule(K []byte, i int) []byte {
+	K = referenceX(K, C[i])
+	K = referenceS(K)
+	K = referenceP(K)
+	K = referenceL(K)
+	return K
+}
+
+func referenceE(K, m []byte) []byte {
+	state := referenceX(K, m)
+	for i := 0; i < 12; i++ {
+		state = referenceS(state)
+		state = referenceP(state)
+		state = referenceL(state)
+		K = referenceKeySchedule(K, i)
+		state = referenceX(state, K)
+	}
+	return state
+}
+
+// referenceG is the compression function built from the byte-oriented
+// transformations above.
+func referenceG(N, m, h []byte) []byte {
+	K := referenceX(h, N)
+	K = referenceS(K)
+	K = referenceP(K)
+	K = referenceL(K)
+	t := referenceE(K, m)
+	t = referenceX(h, t)
+	return referenceX(t, m)
+}
+
+func TestCompress(t *testing.T) {
+	var h, n, m [BlockSize]byte
+	for i := 0; i < 16; i++ {
+		for j := range h {
+			h[j] = byte(i*j + 1)
+			n[j] = byte(i + 3*j)
+			m[j] = byte(7*i ^ j)
+		}
+
+		want := referenceG(n[:], m[:], h[:])
+
+		h64, n64, m64 := loadBlock(h[:]), loadBlock(n[:]), loadBlock(m[:])
+		compress(&h64, &n64, &m64)
+		got := make([]byte, BlockSize)
+		for j, w := range h64 {
+			binary.LittleEndian.PutUint64(got[8*j:], w)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Fatalf("compress(%x, %x, %x) = %x, want %x", h, n, m, got, want)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	k := make([]byte, BlockSize)
+	m := make([]byte, BlockSize)
+	for i := range k {
+		k[i] = byte(5*i + 3)
+		m[i] = byte(i * i)
+	}
+
+	if got, want := X(k, m), referenceX(k, m); !bytes.Equal(got, want) {
+		t.Errorf("X = %x, want %x", got, want)
+	}
+	if got, want := S(k), referenceS(k); !bytes.Equal(got, want) {
+		t.Errorf("S = %x, want %x", got, want)
+	}
+	if got, want := P(k), referenceP(k); !bytes.Equal(got, want) {
+		t.Errorf("P = %x, want %x", got, want)
+	}
+	if got, want := L(k), referenceL(k); !bytes.Equal(got, want) {
+		t.Errorf("L = %x, want %x", got, want)
+	}
+	if got, want := KeySchedule(k, 5), referenceKeySchedule(k, 5); !bytes.Equal(got, want) {
+		t.Errorf("KeySchedule = %x, want %x", got, want)
+	}
+	if got, want := E(k, m), referenceE(k, m); !bytes.Equal(got, want) {
+		t.Errorf("E = %x, want %x", got, want)
+	}
+}
+
+func BenchmarkCompress(b *testing.B) {
+	var h, n, m [8]uint64
+	b.SetBytes(BlockSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		compress(&h, &n, &m)
+	}
+}
+
+// BenchmarkReferenceCompress runs the byte-oriented compression function
+// for comparison with BenchmarkCompress.
+func BenchmarkReferenceCompress(b *testing.B) {
+	h := make([]byte, BlockSize)
+	n := make([]byte, BlockSize)
+	m := make([]byte, BlockSize)
+	b.SetBytes(BlockSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h = referenceG(n, m, h)
+	}
+}
+
+func BenchmarkStribog512(b *testing.B) {
+	s := New512()
+	buf := make([]byte, 8192)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.Write(buf)
+	}
+}
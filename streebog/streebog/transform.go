@@ -0,0 +1,92 @@
+package streebog
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The byte-oriented transformations of GOST R 34.11-2012 on 64-byte
+// values. Hash and Stribog use the LPS tables instead; these remain for
+// callers that build on the individual steps.
+
+// X returns k XOR a. It panics if their lengths differ.
+func X(k []byte, a []byte) []byte {
+	var res []byte
+	if len(k) != len(a) {
+		panic(fmt.Sprintf("Different lengths! %d != %d", len(k), len(a)))
+	}
+	for i := 0; i < len(k); i++ {
+		res = append(res, k[i]^a[i])
+	}
+	return res
+}
+
+// S substitutes every byte of a with PI.
+func S(a []byte) []byte {
+	var res []byte
+	for i := 0; i < len(a); i++ {
+		res = append(res, PI[a[i]])
+	}
+	return res
+}
+
+// Tau is the byte transposition applied by P.
+var Tau = []byte{
+	0, 8, 16, 24, 32, 40, 48, 56,
+	1, 9, 17, 25, 33, 41, 49, 57,
+	2, 10, 18, 26, 34, 42, 50, 58,
+	3, 11, 19, 27, 35, 43, 51, 59,
+	4, 12, 20, 28, 36, 44, 52, 60,
+	5, 13, 21, 29, 37, 45, 53, 61,
+	6, 14, 22, 30, 38, 46, 54, 62,
+	7, 15, 23, 31, 39, 47, 55, 63,
+}
+
+// P transposes the bytes of a as an 8x8 matrix.
+func P(a []byte) []byte {
+	var res []byte
+	for i := 0; i < len(a); i++ {
+		res = append(res, a[Tau[i]])
+	}
+	return res
+}
+
+// L multiplies every 64-bit word of a, read little-endian, by the matrix
+// A. The most significant bit of a word selects A[0].
+func L(a []byte) []byte {
+	res := make([]byte, 64)
+	for i := 0; i < 64; i += 8 {
+		x := binary.LittleEndian.Uint64(a[i:])
+		var y uint64
+		for j := 0; j < 64; j++ {
+			if (x>>(63-j))&1 == 1 {
+				y ^= A[j]
+			}
+		}
+		binary.LittleEndian.PutUint64(res[i:], y)
+	}
+	return res
+}
+
+// KeySchedule derives the round key i+1 from the round key K.
+func KeySchedule(K []byte, i int) []byte {
+	K = X(K, C[i])
+	K = S(K)
+	K = P(K)
+	K = L(K)
+	return K
+}
+
+// E encrypts m under K with the 12-round cipher of the compression
+// function.
+func E(K, m []byte) []byte {
+	state := X(K, m)
+	for i := 0; i < 12; i++ {
+		state = S(state)
+		state = P(state)
+		state = L(state)
+		K = KeySchedule(K, i)
+		state = X(state, K)
+	}
+	return state
+}